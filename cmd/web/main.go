@@ -13,6 +13,7 @@ import (
 	"sync"
 
 	"vlsa/internal/bus"
+	"vlsa/internal/git"
 	vlsaLog "vlsa/internal/log"
 )
 
@@ -37,7 +38,7 @@ func main() {
 	// API routes
 	http.HandleFunc("/api/upload", handleUpload)
 	http.HandleFunc("/api/logs", handleLogs)
-	http.HandleFunc("/api/logs/", handleLogDetail) // For /api/logs/{id}/source and /api/logs/{id}
+	http.HandleFunc("/api/logs/", handleLogDetail) // For /api/logs/{id}/source, /api/logs/{id}/blame and /api/logs/{id}
 	
 	// Serve main page
 	http.HandleFunc("/", handleIndex)
@@ -115,7 +116,7 @@ func handleUpload(w http.ResponseWriter, r *http.Request) {
 	// Process logs using existing VLSA logic
 	logChannel := make(chan vlsaLog.LogProcessingMsg)
 	go func() {
-		vlsaLog.ProcessLogs(tempFile.Name(), logChannel)
+		vlsaLog.ProcessLogs(tempFile.Name(), vlsaLog.Options{Format: r.FormValue("format"), Since: r.FormValue("since"), Dependencies: r.FormValue("deps") == "true"}, logChannel)
 	}()
 	
 	fmt.Printf("[WEB] Waiting for log processing to complete...\n")
@@ -166,16 +167,56 @@ func handleLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// Optional filters, e.g. /api/logs?level=error&host=web-1
+	query := r.URL.Query()
+	filters := map[string]func(vlsaLog.Log) string{
+		"service":  func(l vlsaLog.Log) string { return l.Service },
+		"host":     func(l vlsaLog.Log) string { return l.Host },
+		"level":    func(l vlsaLog.Log) string { return l.Level },
+		"trace_id": func(l vlsaLog.Log) string { return l.TraceID },
+	}
+	function := strings.ToLower(query.Get("func"))
+	
 	logsMutex.RLock()
-	logs := make([]map[string]interface{}, len(currentLogs))
+	logs := []map[string]interface{}{}
 	for i, log := range currentLogs {
-		logs[i] = map[string]interface{}{
-			"id":      i,
-			"time":    log.Time.Format("15:04:05"),
-			"service": log.Service,
-			"message": log.Message,
-			"sources": len(log.Sources),
+		matches := true
+		for name, field := range filters {
+			if v := query.Get(name); v != "" && !strings.EqualFold(field(log), v) {
+				matches = false
+			}
+		}
+		if function != "" {
+			found := false
+			for _, s := range log.Sources {
+				if strings.Contains(strings.ToLower(s.Symbol()), function) {
+					found = true
+				}
+			}
+			matches = matches && found
+		}
+		// Only logs from code changed since the upload's since revision
+		if query.Get("changed") == "true" && !log.Changed() {
+			matches = false
 		}
+		if !matches {
+			continue
+		}
+		
+		logs = append(logs, map[string]interface{}{
+			"id":         i,
+			"time":       log.Time.Format("15:04:05"),
+			"service":    log.Service,
+			"host":       log.Host,
+			"level":      log.Level,
+			"traceId":    log.TraceID,
+			"spanId":     log.SpanID,
+			"message":    log.Message,
+			"attributes": log.Attributes,
+			"raw":        log.Raw,
+			"sources":    len(log.Sources),
+			"changed":    log.Changed(),
+		})
 	}
 	logsMutex.RUnlock()
 	
@@ -199,6 +240,43 @@ func handleLogDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// Handle GET request for who last changed the source's line. Blame
+	// runs git, so the logs are only locked to find the source.
+	if len(parts) == 2 && parts[1] == "blame" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		
+		logsMutex.RLock()
+		if logID < 0 || logID >= len(currentLogs) {
+			logsMutex.RUnlock()
+			http.Error(w, "Log not found", http.StatusNotFound)
+			return
+		}
+		currentLog := currentLogs[logID]
+		source := vlsaLog.SourceMapping{}
+		if len(currentLog.Sources) > 0 {
+			source, _ = selectedSource(currentLog, r)
+		}
+		logsMutex.RUnlock()
+		if source.Path == "" {
+			http.Error(w, "Log has no source", http.StatusNotFound)
+			return
+		}
+		
+		// Blame is cached per file and commit
+		rev, file := vlsaLog.SplitRevision(source.Path)
+		blame, err := git.BlameLineAt(rev, file, source.Line)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(blame)
+		return
+	}
+	
 	logsMutex.Lock()
 	defer logsMutex.Unlock()
 	
@@ -228,14 +306,6 @@ func handleLogDetail(w http.ResponseWriter, r *http.Request) {
 		
 		currentLog := currentLogs[logID]
 		
-		// Get selected source index from query parameter, default to 0
-		sourceIdx := 0
-		if idx := r.URL.Query().Get("source"); idx != "" {
-			if parsed, err := strconv.Atoi(idx); err == nil && parsed < len(currentLog.Sources) {
-				sourceIdx = parsed
-			}
-		}
-		
 		if len(currentLog.Sources) == 0 {
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(map[string]interface{}{
@@ -247,26 +317,57 @@ func handleLogDetail(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		
-		if sourceIdx >= len(currentLog.Sources) {
-			sourceIdx = 0
-		}
+		source, sourceIdx := selectedSource(currentLog, r)
 		
-		source := currentLog.Sources[sourceIdx]
+		// Log statements expected after this log that never showed up
+		missing := []map[string]interface{}{}
+		for _, m := range currentLog.Missing {
+			if m.Path == source.Path {
+				missing = append(missing, map[string]interface{}{
+					"line":    m.Line,
+					"message": m.DisplayMessage,
+				})
+			}
+		}
 		
 		// Build sources list for frontend
 		sources := make([]map[string]interface{}, len(currentLog.Sources))
 		for i, s := range currentLog.Sources {
+			callers := make([]map[string]interface{}, len(s.Callers))
+			for j, c := range s.Callers {
+				callers[j] = map[string]interface{}{
+					"path":     c.Path,
+					"line":     c.Line,
+					"function": c.Function,
+					"symbol":   c.Symbol(),
+				}
+			}
 			sources[i] = map[string]interface{}{
 				"path": s.Path,
 				"line": s.Line,
+
+				"display":  s.DisplayPath(),
+				"module":   s.Module,
+				"function": s.Function,
+				"type":     s.Type,
+				"package":  s.Package,
+				"symbol":   s.Symbol(),
+				"callers":  callers,
 			}
 		}
 		
+		// Sources of older logs may be read from the commit that was deployed
+		revision, file := vlsaLog.SplitRevision(source.Path)
+		
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"path":         source.Path,
+			"path":         file,
+			"display":      source.DisplayPath(),
+			"revision":     revision,
 			"line":         source.Line,
 			"content":      source.SourceCode,
+			"symbol":       source.Symbol(),
+			"missing":      missing,
 			"sources":      sources,
 			"selectedIdx":  sourceIdx,
 		})
@@ -276,6 +377,25 @@ func handleLogDetail(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "Invalid path", http.StatusBadRequest)
 }
 
+// Returns the source picked with ?source=i, defaulting to the first,
+// or the caller of a logging wrapper picked with ?source=i&caller=j.
+func selectedSource(l vlsaLog.Log, r *http.Request) (vlsaLog.SourceMapping, int) {
+	sourceIdx := 0
+	if idx := r.URL.Query().Get("source"); idx != "" {
+		if parsed, err := strconv.Atoi(idx); err == nil && parsed >= 0 && parsed < len(l.Sources) {
+			sourceIdx = parsed
+		}
+	}
+	
+	source := l.Sources[sourceIdx]
+	if idx := r.URL.Query().Get("caller"); idx != "" {
+		if parsed, err := strconv.Atoi(idx); err == nil && parsed >= 0 && parsed < len(source.Callers) {
+			source = source.Callers[parsed]
+		}
+	}
+	return source, sourceIdx
+}
+
 func getFileExtension(filename string) string {
 	ext := filepath.Ext(filename)
 	if ext != "" {
@@ -136,7 +136,7 @@ function renderLogsTable() {
         row.innerHTML = `
             <td>${log.time}</td>
             <td>${escapeHtml(log.service || '')}</td>
-            <td class="message-cell">${escapeHtml(log.message)}</td>
+            <td class="message-cell">${log.changed ? '<span class="changed-marker" title="Source changed since the since revision">Δ</span> ' : ''}${escapeHtml(log.message)}</td>
             <td class="sources-count">${log.sources}</td>
             <td>
                 <button class="delete-btn" onclick="deleteLog(${log.id})">Delete</button>
@@ -191,7 +191,7 @@ function renderSourceCode(sourceData) {
     }
     
     // Update source info
-    sourceInfo.textContent = `${sourceData.path}:${sourceData.line}`;
+    sourceInfo.textContent = `${sourceData.revision ? sourceData.path : sourceData.display}:${sourceData.line}` + (sourceData.symbol ? ` in ${sourceData.symbol}` : '') + (sourceData.revision ? ` at commit ${sourceData.revision.slice(0, 8)}` : '');
     
     // Handle multiple sources
     if (sourceData.sources && sourceData.sources.length > 1) {
@@ -204,6 +204,7 @@ function renderSourceCode(sourceData) {
     // Render source code with line highlighting
     const lines = sourceData.content.split('\n');
     const targetLine = sourceData.line;
+    const missingLines = new Set((sourceData.missing || []).map(m => m.line));
     
     let highlightedContent = '';
     lines.forEach((line, index) => {
@@ -212,6 +213,8 @@ function renderSourceCode(sourceData) {
         
         if (isTargetLine) {
             highlightedContent += `<span class="highlight-line">${escapeHtml(line)}</span>\n`;
+        } else if (missingLines.has(lineNumber)) {
+            highlightedContent += `<span class="missing-line" title="Expected, not seen">${escapeHtml(line)}</span>\n`;
         } else {
             highlightedContent += escapeHtml(line) + '\n';
         }
@@ -226,7 +229,7 @@ function populateSourceSelector(sources, selectedIdx) {
     sources.forEach((source, index) => {
         const option = document.createElement('option');
         option.value = index;
-        option.textContent = `${source.path}:${source.line}`;
+        option.textContent = `${source.display || source.path}:${source.line}` + (source.symbol ? ` (${source.symbol})` : '');
         option.selected = index === selectedIdx;
         sourceSelector.appendChild(option);
     });
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"vlsa/internal/log"
+)
+
+// Maps the logs in the file to their sources without the TUI. The bus
+// has to be read already, see drainBus.
+func loadLogs(path string, opts log.Options) ([]log.Log, error) {
+	logChannel := make(chan log.LogProcessingMsg)
+	go log.ProcessLogs(path, opts, logChannel)
+
+	var result log.LogProcessingMsg
+	for msg := range logChannel {
+		result = msg
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("%s", result.Error)
+	}
+	return result.Logs, nil
+}
+
+// Reports which log statements in the source were seen in the logs.
+func runCoverage(args []string) {
+	fs := flag.NewFlagSet("coverage", flag.ExitOnError)
+	source := sourceFlags(fs)
+	html := fs.String("html", "", "also write an HTML report with the annotated source to this file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: vlsa coverage [flags] <log file>\n\nReports which log statements in the source were seen in the logs.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	opts, err := source.options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	flush := drainBus()
+	logs, err := loadLogs(fs.Arg(0), opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	report := log.Coverage(logs)
+	flush()
+	report.WriteText(os.Stdout)
+
+	if *html != "" {
+		err := writeFile(*html, report.WriteHTML)
+		flush()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("\nWrote %s\n", *html)
+	}
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %v", path, err)
+	}
+	defer f.Close()
+	return write(f)
+}
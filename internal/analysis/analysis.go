@@ -0,0 +1,183 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"sync"
+)
+
+// A parsed Go file. Files are parsed once and shared by every lookup.
+type File struct {
+	Path string
+	Fset *token.FileSet
+	AST  *ast.File
+	src  string
+}
+
+var (
+	fileCache = map[string]*File{}
+	cacheMu   sync.Mutex
+)
+
+// Parses the Go source of the file at path. The source is passed in
+// since it may come from somewhere other than the working tree.
+func ParseFile(path string, src string) (*File, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if f, ok := fileCache[path]; ok && f.src == src {
+		return f, nil
+	}
+
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil && astFile == nil {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+	f := &File{Path: path, Fset: fset, AST: astFile, src: src}
+	fileCache[path] = f
+	return f, nil
+}
+
+// Line of the position in the file.
+func (f *File) Line(p token.Pos) int {
+	return f.Fset.Position(p).Line
+}
+
+// A function or method declared in a Go file.
+type Func struct {
+	Package  string
+	Receiver string // Type of the receiver for methods, like *Server
+	Name     string
+	Path     string
+	Start    int // Line of the func keyword
+	End      int // Line of the closing brace
+	Decl     *ast.FuncDecl
+	File     *File
+}
+
+// Returns the name the way Go prints it in stacks, pkg.(*T).Name.
+func (f Func) String() string {
+	if f.Receiver == "" {
+		return f.Package + "." + f.Name
+	}
+	if strings.HasPrefix(f.Receiver, "*") {
+		return fmt.Sprintf("%s.(%s).%s", f.Package, f.Receiver, f.Name)
+	}
+	return fmt.Sprintf("%s.%s.%s", f.Package, f.Receiver, f.Name)
+}
+
+// Identifies the function across files.
+func (f Func) ID() string {
+	return fmt.Sprintf("%s:%d", f.Path, f.Start)
+}
+
+// Returns every function declared in the file.
+func (f *File) Funcs() []Func {
+	funcs := []Func{}
+	for _, d := range f.AST.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok || fd.Body == nil {
+			continue
+		}
+		fn := Func{
+			Package: f.AST.Name.Name,
+			Name:    fd.Name.Name,
+			Path:    f.Path,
+			Start:   f.Line(fd.Pos()),
+			End:     f.Line(fd.End()),
+			Decl:    fd,
+			File:    f,
+		}
+		if fd.Recv != nil && len(fd.Recv.List) > 0 {
+			fn.Receiver = typeName(fd.Recv.List[0].Type)
+		}
+		funcs = append(funcs, fn)
+	}
+	return funcs
+}
+
+// Finds the function whose body holds the line.
+func EnclosingFunc(path string, src string, line int) (Func, bool) {
+	f, err := ParseFile(path, src)
+	if err != nil {
+		return Func{}, false
+	}
+	for _, fn := range f.Funcs() {
+		if fn.Start <= line && line <= fn.End {
+			return fn, true
+		}
+	}
+	return Func{}, false
+}
+
+// Renders a receiver type like *Server or List[T] without its type parameters.
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + typeName(t.X)
+	case *ast.IndexExpr:
+		return typeName(t.X)
+	case *ast.IndexListExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return typeName(t.X) + "." + t.Sel.Name
+	}
+	return ""
+}
+
+// Reports whether the function does nothing but log, like a
+// LogAuthFailure helper wrapping log.Println. Such wrappers hide
+// the interesting location, which is whoever called them. Besides
+// logging, the body may only format the message and return.
+func (f Func) IsWrapper() bool {
+	stmts := f.Decl.Body.List
+	if len(stmts) == 0 || len(stmts) > 3 {
+		return false
+	}
+	logs := 0
+	for _, s := range stmts {
+		switch st := s.(type) {
+		case *ast.ExprStmt:
+			call, ok := st.X.(*ast.CallExpr)
+			if !ok {
+				return false
+			}
+			if _, ok := goLogCall(f.File, call); !ok {
+				return false
+			}
+			logs++
+		case *ast.AssignStmt:
+			for _, rhs := range st.Rhs {
+				if !isFormatCall(rhs) {
+					return false
+				}
+			}
+		case *ast.ReturnStmt:
+			if len(st.Results) > 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return logs > 0
+}
+
+// Reports whether the expression only formats a message, like fmt.Sprintf.
+func isFormatCall(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "fmt" && strings.HasPrefix(sel.Sel.Name, "Sprint")
+}
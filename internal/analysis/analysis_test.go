@@ -0,0 +1,288 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const wrapperSrc = `package lib
+
+import "log"
+
+func LogAuthFailure() {
+	log.Println("Authentication failed for user")
+}
+
+func (s *Server) handle(email string) bool {
+	if email == "" {
+		LogAuthFailure()
+		return false
+	}
+	log.Println("handled")
+	return true
+}
+`
+
+func TestEnclosingFunc(t *testing.T) {
+	fn, ok := EnclosingFunc("lib/logger.go", wrapperSrc, 6)
+	if !ok || fn.String() != "lib.LogAuthFailure" || fn.Start != 5 || fn.End != 7 {
+		t.Fatalf("Unexpected function %+v", fn)
+	}
+	if !fn.IsWrapper() {
+		t.Errorf("Expected %s to be a logging wrapper", fn)
+	}
+
+	fn, ok = EnclosingFunc("lib/logger.go", wrapperSrc, 14)
+	if !ok || fn.String() != "lib.(*Server).handle" || fn.IsWrapper() {
+		t.Errorf("Unexpected function %+v", fn)
+	}
+
+	// Functions that only call other things aren't logging
+	for _, src := range []string{
+		"package lib\n\nfunc save() {\n\tdb.Exec()\n\tcache.Clear()\n}\n",
+		"package lib\n\nfunc save() {\n\tlog.Println(\"saving\")\n\tdb.Exec()\n}\n",
+	} {
+		if fn, ok := EnclosingFunc("lib/save.go", src, 4); !ok || fn.IsWrapper() {
+			t.Errorf("Expected %q not to be a logging wrapper", src)
+		}
+	}
+	src := "package lib\n\nfunc warn(format string, args ...any) {\n\tmsg := fmt.Sprintf(format, args...)\n\tlog.Println(msg)\n}\n"
+	if fn, ok := EnclosingFunc("lib/warn.go", src, 4); !ok || !fn.IsWrapper() {
+		t.Errorf("Expected a wrapper formatting its message to be a logging wrapper")
+	}
+
+	if _, ok := EnclosingFunc("lib/logger.go", wrapperSrc, 3); ok {
+		t.Errorf("Expected no function around the imports")
+	}
+}
+
+func TestIndexCallers(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, src string) {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("lib/logger.go", wrapperSrc)
+	write("app/main.go", "package main\n\nimport \"example.com/x/lib\"\n\nfunc main() {\n\tlib.LogAuthFailure()\n}\n")
+	write("app/main_test.go", "package main\n\nimport \"example.com/x/lib\"\n\nfunc TestX() {\n\tlib.LogAuthFailure()\n}\n")
+
+	ix := Load(root, Dir(root))
+	fn, ok := ix.FuncAt(filepath.Join(root, "lib/logger.go"), 6)
+	if !ok {
+		t.Fatalf("Expected to find the wrapper in %+v", ix.Funcs)
+	}
+
+	callers := ix.Callers(fn)
+	if len(callers) != 2 {
+		t.Fatalf("Expected 2 callers, got %+v", callers)
+	}
+	if callers[0].Caller.String() != "main.main" || callers[0].Line != 6 {
+		t.Errorf("Unexpected caller %+v", callers[0])
+	}
+	if callers[1].Caller.String() != "lib.(*Server).handle" || callers[1].Line != 11 {
+		t.Errorf("Unexpected caller %+v", callers[1])
+	}
+}
+
+func TestEnclosingTextFunc(t *testing.T) {
+	py := "import logging\n\nclass Audit:\n    def denied(self, user):\n        # record it\n        logger.warning(\"Access denied\")\n\n    def other(self):\n        logger.info(\"a\")\n        logger.info(\"b\")\n"
+	fn, ok := EnclosingTextFunc("audit.py", py, 6)
+	if !ok || fn.Name != "denied" || fn.Type != "Audit" || !fn.IsWrapper() {
+		t.Errorf("Unexpected function %+v", fn)
+	}
+	if fn, ok := EnclosingTextFunc("audit.py", py, 10); !ok || fn.Name != "other" || fn.IsWrapper() {
+		t.Errorf("Unexpected function %+v", fn)
+	}
+
+	rs := "impl Engine {\n    fn load(&self) {\n        warn!(\"rules {} missing\", id);\n    }\n}\n"
+	fn, ok = EnclosingTextFunc("engine.rs", rs, 3)
+	if !ok || fn.Name != "load" || fn.Type != "Engine" || fn.End != 4 || !fn.IsWrapper() {
+		t.Errorf("Unexpected function %+v", fn)
+	}
+}
+
+const pathSrc = `package main
+
+func authenticateUser(email string) bool {
+	log.Printf("User login attempt for %s", email)
+	user, err := find(email)
+	if err != nil {
+		return false
+	}
+	if user.Admin {
+		audit(user)
+	} else {
+		log.Println("User login successful")
+		return true
+	}
+	log.Println("Authentication failed for user")
+	return false
+}
+`
+
+func TestPathBetween(t *testing.T) {
+	fn, ok := EnclosingFunc("auth.go", pathSrc, 4)
+	if !ok {
+		t.Fatal("Expected to find authenticateUser")
+	}
+
+	// Logging the success means the admin branch and the error return didn't run
+	path, ok := fn.PathBetween(4, 12)
+	if !ok {
+		t.Fatal("Expected a path between the logs")
+	}
+	for _, l := range []int{4, 5, 6, 9, 12} {
+		if !path.Ran[l] {
+			t.Errorf("Expected line %d to have run, ran %v", l, path.Ran)
+		}
+	}
+	for _, l := range []int{7, 10} {
+		if !path.Skipped[l] {
+			t.Errorf("Expected line %d to be skipped, skipped %v", l, path.Skipped)
+		}
+	}
+	if path.Ran[15] {
+		t.Errorf("Expected nothing after the to line to run")
+	}
+
+	// Failing means the else branch didn't run
+	path, _ = fn.PathBetween(4, 15)
+	if !path.Skipped[12] || !path.Skipped[7] || !path.Ran[10] {
+		t.Errorf("Unexpected path ran %v skipped %v", path.Ran, path.Skipped)
+	}
+
+	if _, ok := fn.PathBetween(4, 40); ok {
+		t.Errorf("Expected no path to a line outside the function")
+	}
+}
+
+func TestCallPath(t *testing.T) {
+	root := t.TempDir()
+	src := `package main
+
+type Store struct{}
+
+func (s *Store) Find(email string) {
+	log.Println("looking up user")
+}
+
+func authenticate(s *Store, email string) {
+	s.Find(email)
+}
+
+func handleLogin(s *Store, email string) {
+	log.Println("login attempt")
+	validate(email)
+	authenticate(s, email)
+}
+
+func validate(email string) {
+	log.Println("validating")
+}
+`
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ix := Load(root, Dir(root))
+	funcAt := func(line int) Func {
+		fn, ok := ix.FuncAt(filepath.Join(root, "main.go"), line)
+		if !ok {
+			t.Fatalf("No function at line %d", line)
+		}
+		return fn
+	}
+
+	// Straight down from the handler to the store
+	steps, ok := ix.CallPath(funcAt(14), funcAt(6))
+	if !ok || len(steps) != 3 {
+		t.Fatalf("Expected handleLogin → authenticate → Find, got %+v", steps)
+	}
+	if steps[0].Line != 16 || steps[1].Func.Name != "authenticate" || steps[1].Line != 10 || steps[2].Func.String() != "main.(*Store).Find" {
+		t.Errorf("Unexpected steps %+v", steps)
+	}
+
+	// Back up to the handler after validating, then down to the store
+	steps, ok = ix.CallPath(funcAt(20), funcAt(6))
+	if !ok || len(steps) != 4 || !steps[1].Returned || steps[1].Func.Name != "handleLogin" || steps[1].Line != 16 {
+		t.Errorf("Expected validate ↩ handleLogin → authenticate → Find, got %+v", steps)
+	}
+}
+
+func TestLogsAfter(t *testing.T) {
+	src := `package main
+
+func save(u User) error {
+	log.Info("saving user")
+	if err := validate(u); err != nil {
+		log.Warnf("invalid user %s", u.ID)
+		return err
+	}
+	logger.Debug("validated")
+	for _, h := range hooks {
+		log.Printf("running hook %s", h)
+	}
+	if err := db.Save(u); err != nil {
+		return fmt.Errorf("error saving: %v", err)
+	}
+	log.Info("user saved")
+	return nil
+}
+`
+	fn, ok := EnclosingFunc("save.go", src, 4)
+	if !ok {
+		t.Fatal("Expected to find save")
+	}
+
+	expected := fn.LogsAfter(4)
+	if len(expected) != 2 || expected[0].Message != "validated" || expected[0].Level != "debug" || expected[1].Message != "user saved" || expected[1].Line != 16 {
+		t.Errorf("Unexpected logs after the first one %+v", expected)
+	}
+
+	// Nothing follows a log in a branch that returns
+	if after := fn.LogsAfter(6); len(after) != 0 {
+		t.Errorf("Expected nothing after the early return, got %+v", after)
+	}
+
+	// Leaving the loop carries on to the rest of the function
+	if after := fn.LogsAfter(11); len(after) != 1 || after[0].Message != "user saved" {
+		t.Errorf("Unexpected logs after the loop %+v", after)
+	}
+
+	if len(fn.LogStatements()) != 5 {
+		t.Errorf("Expected 5 log statements, fmt.Errorf isn't one, got %+v", fn.LogStatements())
+	}
+}
+
+func TestTextLogStatements(t *testing.T) {
+	src := `import logging
+logger = logging.getLogger(__name__)
+
+def run(job):
+    logger.info("starting %s", job)
+    # logger.debug("not a statement")
+    logger.warning('queue is full')
+    logger.error(build_message(job))
+`
+	statements := TextLogStatements("worker.py", src)
+	if len(statements) != 3 {
+		t.Fatalf("Expected 3 log statements, got %+v", statements)
+	}
+	if s := statements[0]; s.Line != 5 || s.Level != "info" || s.Message != "starting %s" || s.Function != "run" {
+		t.Errorf("Unexpected first statement %+v", s)
+	}
+	if s := statements[1]; s.Level != "warn" || s.Message != "queue is full" {
+		t.Errorf("Unexpected second statement %+v", s)
+	}
+	if s := statements[2]; s.Message != "" || !s.Dynamic {
+		t.Errorf("Expected the last statement to be dynamic, got %+v", s)
+	}
+
+	rust := "fn start() {\n    info!(target: \"engine\", \"engine started\");\n}\n"
+	if statements := TextLogStatements("main.rs", rust); len(statements) != 1 || statements[0].Message != "engine started" {
+		t.Errorf("Unexpected Rust statements %+v", statements)
+	}
+}
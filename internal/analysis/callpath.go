@@ -0,0 +1,94 @@
+package analysis
+
+// A function on the way from one function to another.
+type Step struct {
+	Func     Func
+	Line     int  // Line in the function execution went on from
+	Returned bool // Whether execution got here by returning rather than by a call
+}
+
+// Longest chain of calls searched for between two functions.
+const maxCallDepth = 8
+
+type callNode struct {
+	fn    Func
+	prev  *callNode
+	line  int  // Call site, in this function when returned to, otherwise in prev
+	up    bool // Whether this function was returned to
+	down  bool // Whether the path has called down, after which it can't return
+	depth int
+}
+
+// Finds the shortest chain of calls leading from one function to
+// another. Execution may first return from the function to one of its
+// callers before calling down to the other, like a handler that logs,
+// returns, and whose caller goes on to the next step. The first and
+// last steps are the functions themselves.
+func (ix *Index) CallPath(from, to Func) ([]Step, bool) {
+	ix.graphOnce.Do(ix.buildGraph)
+	if from.ID() == to.ID() {
+		return nil, false
+	}
+
+	type state struct {
+		id   string
+		down bool
+	}
+	queue := []*callNode{{fn: from}}
+	seen := map[state]bool{{from.ID(), false}: true}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n.fn.ID() == to.ID() {
+			return n.steps(), true
+		}
+		if n.depth >= maxCallDepth {
+			continue
+		}
+
+		next := []*callNode{}
+		if !n.down {
+			// Return to each caller, at the line it made the call
+			for _, c := range ix.callers[n.fn.ID()] {
+				next = append(next, &callNode{fn: c.Caller, prev: n, line: c.Line, up: true, depth: n.depth + 1})
+			}
+		}
+		for _, c := range ix.calls[n.fn.ID()] {
+			for _, callee := range ix.Callees(c) {
+				next = append(next, &callNode{fn: callee, prev: n, line: c.Line, down: true, depth: n.depth + 1})
+			}
+		}
+
+		for _, m := range next {
+			s := state{m.fn.ID(), m.down}
+			if seen[s] {
+				continue
+			}
+			seen[s] = true
+			queue = append(queue, m)
+		}
+	}
+	return nil, false
+}
+
+// Walks back from the end of the path to build its steps in order.
+func (n *callNode) steps() []Step {
+	nodes := []*callNode{}
+	for c := n; c != nil; c = c.prev {
+		nodes = append([]*callNode{c}, nodes...)
+	}
+
+	steps := []Step{}
+	for i, c := range nodes {
+		s := Step{Func: c.fn, Line: c.fn.Start, Returned: c.up}
+		if c.up {
+			s.Line = c.line
+		}
+		// Execution leaves the function at the call to the next one
+		if i+1 < len(nodes) && !nodes[i+1].up {
+			s.Line = nodes[i+1].line
+		}
+		steps = append(steps, s)
+	}
+	return steps
+}
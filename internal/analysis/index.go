@@ -0,0 +1,240 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A call from one function to another, as written in the source.
+type Call struct {
+	Caller    Func   // Function the call is made in
+	Name      string // Name of the called function or method
+	Qualifier string // Package or value the name is selected from, if any
+	Package   bool   // Whether the qualifier is an imported package
+	Target    string // ID of the called function when the type checker resolved it
+	Path      string
+	Line      int
+}
+
+// Every function and call in the Go files under a source root.
+type Index struct {
+	Root  string
+	Funcs []Func
+	Calls []Call
+
+	fset      *token.FileSet
+	graphOnce sync.Once
+	callers   map[string][]Call // Calls to each function, by ID
+	calls     map[string][]Call // Calls made in each function, by ID
+	byName    map[string][]Func
+}
+
+var (
+	indexCache = map[string]*Index{}
+	indexMu    sync.Mutex
+)
+
+// Directories that never hold the source being analyzed.
+var skipDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true, "testdata": true}
+
+// Parses and type checks every Go file under the root, skipping tests.
+// Indexes are built once per root.
+func Load(root string, src Source) *Index {
+	if root == "" {
+		root = "."
+	}
+	indexMu.Lock()
+	defer indexMu.Unlock()
+	if ix, ok := indexCache[root]; ok {
+		return ix
+	}
+
+	ix := &Index{Root: root, fset: token.NewFileSet()}
+	packages := map[string][]*File{} // Files of each package, by directory and name
+	order := []string{}
+	for _, p := range src.Files() {
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") || skippedFile(root, p) {
+			continue
+		}
+		code, err := src.Read(p)
+		if err != nil {
+			continue
+		}
+		// Files of a package share a file set so they can be type checked together
+		astFile, _ := parser.ParseFile(ix.fset, p, code, parser.SkipObjectResolution)
+		if astFile == nil {
+			continue
+		}
+		f := &File{Path: p, Fset: ix.fset, AST: astFile, src: code}
+		key := filepath.Dir(p) + "\x00" + astFile.Name.Name
+		if _, ok := packages[key]; !ok {
+			order = append(order, key)
+		}
+		packages[key] = append(packages[key], f)
+		ix.Funcs = append(ix.Funcs, f.Funcs()...)
+	}
+
+	ids := map[string]bool{}
+	for _, fn := range ix.Funcs {
+		ids[fn.ID()] = true
+	}
+	for _, key := range order {
+		files := packages[key]
+		info := ix.check(files)
+		for _, f := range files {
+			ix.add(f, info, ids)
+		}
+	}
+
+	indexCache[root] = ix
+	return ix
+}
+
+// Packages imported from outside the root are left empty, the type
+// checker still resolves everything declared within the package.
+type stubImporter map[string]*types.Package
+
+func (s stubImporter) Import(path string) (*types.Package, error) {
+	if p, ok := s[path]; ok {
+		return p, nil
+	}
+	p := types.NewPackage(path, filepath.Base(path))
+	p.MarkComplete()
+	s[path] = p
+	return p, nil
+}
+
+// Type checks the files of a package, ignoring errors from the
+// packages that couldn't be imported.
+func (ix *Index) check(files []*File) *types.Info {
+	astFiles := []*ast.File{}
+	for _, f := range files {
+		astFiles = append(astFiles, f.AST)
+	}
+	info := &types.Info{Uses: map[*ast.Ident]types.Object{}}
+	conf := types.Config{Importer: stubImporter{}, Error: func(error) {}}
+	conf.Check(files[0].AST.Name.Name, ix.fset, astFiles, info)
+	return info
+}
+
+func (ix *Index) add(f *File, info *types.Info, ids map[string]bool) {
+	imports := map[string]bool{}
+	for _, imp := range f.AST.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := filepath.Base(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = true
+	}
+
+	for _, fn := range f.Funcs() {
+		ast.Inspect(fn.Decl.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			c := Call{Caller: fn, Path: f.Path, Line: f.Line(call.Pos())}
+			var ident *ast.Ident
+			switch fun := call.Fun.(type) {
+			case *ast.Ident:
+				ident = fun
+			case *ast.SelectorExpr:
+				ident = fun.Sel
+				if x, ok := fun.X.(*ast.Ident); ok {
+					c.Qualifier = x.Name
+					c.Package = imports[x.Name]
+				} else {
+					c.Qualifier = "_" // A call on an expression, like s.db.Query()
+				}
+			default:
+				return true
+			}
+			c.Name = ident.Name
+
+			// Interface methods resolve to the interface, not to any function
+			// in the index, those are matched by name like unresolved calls
+			if obj, ok := info.Uses[ident].(*types.Func); ok && obj.Pos().IsValid() {
+				pos := ix.fset.Position(obj.Pos())
+				if target := fmt.Sprintf("%s:%d", pos.Filename, pos.Line); ids[target] {
+					c.Target = target
+				}
+			}
+			ix.Calls = append(ix.Calls, c)
+			return true
+		})
+	}
+}
+
+// Reports whether the call could be to the function. Calls the type
+// checker resolved are exact, otherwise methods match any call of
+// the same name on a value.
+func (c Call) Calls(fn Func) bool {
+	if c.Target != "" {
+		return c.Target == fn.ID()
+	}
+	if c.Name != fn.Name {
+		return false
+	}
+	if fn.Receiver != "" {
+		return c.Qualifier != "" && !c.Package
+	}
+	if c.Package {
+		return c.Qualifier == fn.Package
+	}
+	// Unqualified calls stay within the package's directory
+	return c.Qualifier == "" && filepath.Dir(c.Path) == filepath.Dir(fn.Path)
+}
+
+// Returns the calls made to the function.
+func (ix *Index) Callers(fn Func) []Call {
+	ix.graphOnce.Do(ix.buildGraph)
+	return ix.callers[fn.ID()]
+}
+
+// Returns the functions the call could be to.
+func (ix *Index) Callees(c Call) []Func {
+	ix.graphOnce.Do(ix.buildGraph)
+	funcs := []Func{}
+	for _, fn := range ix.byName[c.Name] {
+		if c.Calls(fn) && c.Caller.ID() != fn.ID() {
+			funcs = append(funcs, fn)
+		}
+	}
+	return funcs
+}
+
+func (ix *Index) buildGraph() {
+	ix.callers = map[string][]Call{}
+	ix.calls = map[string][]Call{}
+	ix.byName = map[string][]Func{}
+	for _, fn := range ix.Funcs {
+		ix.byName[fn.Name] = append(ix.byName[fn.Name], fn)
+	}
+	for _, c := range ix.Calls {
+		ix.calls[c.Caller.ID()] = append(ix.calls[c.Caller.ID()], c)
+		for _, fn := range ix.byName[c.Name] {
+			if c.Calls(fn) && c.Caller.ID() != fn.ID() {
+				ix.callers[fn.ID()] = append(ix.callers[fn.ID()], c)
+			}
+		}
+	}
+}
+
+// Finds the indexed function holding the line of the file.
+func (ix *Index) FuncAt(path string, line int) (Func, bool) {
+	path = filepath.Clean(path)
+	for _, fn := range ix.Funcs {
+		if filepath.Clean(fn.Path) == path && fn.Start <= line && line <= fn.End {
+			return fn, true
+		}
+	}
+	return Func{}, false
+}
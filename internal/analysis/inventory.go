@@ -0,0 +1,118 @@
+package analysis
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Extensions of the source files searched for log statements.
+var sourceExts = map[string]bool{
+	".go": true, ".py": true, ".rb": true, ".rs": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true,
+	".java": true, ".kt": true, ".cs": true, ".php": true, ".scala": true,
+}
+
+// Logging calls in languages without a parser here, like logger.info("..."),
+// console.error('...'), LOG.warn("..."), warn!("...") or $this->logger->error('...').
+var textLogCall = regexp.MustCompile(`(?i)(?:\b(?:console|log|logger|logging|slog|self\.log|self\.logger|this\.log|this\.logger|rails\.logger)\.|\$(?:this->)?(?:logger|log)->)(trace|debug|info|notice|warn|warning|error|exception|critical|fatal|log)\s*\(\s*(?:[fr]?"((?:[^"\\]|\\.)*)"|[fr]?'((?:[^'\\]|\\.)*)'|` + "`([^`]*)`" + `)?`)
+var rustLogMacro = regexp.MustCompile(`\b(trace|debug|info|warn|error)!\s*\(`)
+
+// String literals of a Rust log macro, the message is the first one not
+// given as a target: "..." argument.
+var rustLiteral = regexp.MustCompile(`(\w+\s*:\s*)?"((?:[^"\\]|\\.)*)"`)
+
+// Level names used by the other languages, mapped onto the Go ones.
+var textLevels = map[string]string{"notice": "info", "log": "info", "warning": "warn", "exception": "error", "critical": "fatal"}
+
+// Returns the logging calls in a source file of a language other than Go.
+func TextLogStatements(path string, src string) []LogStatement {
+	statements := []LogStatement{}
+	for i, line := range strings.Split(src, "\n") {
+		m := textLogCall.FindStringSubmatch(line)
+		if m == nil {
+			if loc := rustLogMacro.FindStringSubmatchIndex(line); loc != nil {
+				m = []string{line[loc[0]:loc[1]], line[loc[2]:loc[3]], ""}
+				for _, lit := range rustLiteral.FindAllStringSubmatch(line[loc[1]:], -1) {
+					if lit[1] == "" {
+						m[2] = lit[2]
+						break
+					}
+				}
+			}
+		}
+		if m == nil || isCommentText(line) {
+			continue
+		}
+
+		level := strings.ToLower(m[1])
+		if l, ok := textLevels[level]; ok {
+			level = l
+		}
+		s := LogStatement{Path: path, Line: i + 1, EndLine: i + 1, Level: level, Dynamic: true}
+		for _, msg := range m[2:] {
+			if msg != "" {
+				s.Message, s.Dynamic = msg, strings.Contains(msg, "${") || strings.Contains(msg, "#{")
+				break
+			}
+		}
+		if fn, ok := EnclosingTextFunc(path, src, i+1); ok {
+			s.Function = fn.Name
+			if fn.Type != "" {
+				s.Function = fn.Type + "." + fn.Name
+			}
+		}
+		statements = append(statements, s)
+	}
+	return statements
+}
+
+func isCommentText(line string) bool {
+	t := strings.TrimSpace(line)
+	return strings.HasPrefix(t, "//") || strings.HasPrefix(t, "#") || strings.HasPrefix(t, "*") || strings.HasPrefix(t, "/*")
+}
+
+var (
+	inventoryCache = map[string][]LogStatement{}
+	inventoryMu    sync.Mutex
+)
+
+// Returns every log statement in the source files under the root,
+// skipping tests. Inventories are built once per root.
+func LogStatements(root string, src Source) []LogStatement {
+	if root == "" {
+		root = "."
+	}
+	inventoryMu.Lock()
+	defer inventoryMu.Unlock()
+	if statements, ok := inventoryCache[root]; ok {
+		return statements
+	}
+
+	statements := []LogStatement{}
+	for _, p := range src.Files() {
+		if !sourceExts[strings.ToLower(filepath.Ext(p))] || isTest(p) || skippedFile(root, p, "target") {
+			continue
+		}
+		code, err := src.Read(p)
+		if err != nil {
+			continue
+		}
+		if strings.HasSuffix(p, ".go") {
+			if f, err := ParseFile(p, code); err == nil {
+				statements = append(statements, GoLogStatements(f)...)
+			}
+			continue
+		}
+		statements = append(statements, TextLogStatements(p, code)...)
+	}
+
+	inventoryCache[root] = statements
+	return statements
+}
+
+func isTest(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "Test.java") || strings.Contains(filepath.ToSlash(path), "/tests/")
+}
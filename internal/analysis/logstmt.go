@@ -0,0 +1,192 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// A logging call found in the source.
+type LogStatement struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	EndLine  int    `json:"end_line"` // Last line of the call, when it spans several
+	Function string `json:"function"` // Function the call is in
+	Level    string `json:"level"`    // Level named by the call, like error for logger.Errorf
+	Message  string `json:"message"`  // Message template as written, without its quotes
+	Dynamic  bool   `json:"dynamic"`  // Whether the message is built at runtime rather than a literal
+}
+
+// Methods of the common Go loggers, with the level they log at.
+var goLogMethods = map[string]string{
+	"Print": "info", "Printf": "info", "Println": "info",
+	"Trace": "trace", "Tracef": "trace",
+	"Debug": "debug", "Debugf": "debug", "Debugw": "debug", "DebugContext": "debug",
+	"Info": "info", "Infof": "info", "Infow": "info", "InfoContext": "info",
+	"Warn": "warn", "Warnf": "warn", "Warnw": "warn", "Warning": "warn", "Warningf": "warn", "WarnContext": "warn",
+	"Error": "error", "Errorf": "error", "Errorw": "error", "ErrorContext": "error",
+	"Fatal": "fatal", "Fatalf": "fatal", "Fatalln": "fatal", "Fatalw": "fatal",
+	"Panic": "fatal", "Panicf": "fatal", "Panicln": "fatal", "Panicw": "fatal",
+	"Log": "info", "Logf": "info", "LogAttrs": "info",
+}
+
+// Values whose methods share names with logging calls but don't log.
+var notLoggers = map[string]bool{"fmt": true, "errors": true, "t": true, "b": true, "tb": true, "f": true, "xerrors": true, "pkgerrors": true, "http": true}
+
+// Returns the logging call made by the call expression, if it is one.
+func goLogCall(f *File, call *ast.CallExpr) (LogStatement, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return LogStatement{}, false
+	}
+	level, ok := goLogMethods[sel.Sel.Name]
+	// err.Error() and the like take nothing to log
+	if !ok || len(call.Args) == 0 {
+		return LogStatement{}, false
+	}
+	if x, ok := sel.X.(*ast.Ident); ok && notLoggers[x.Name] {
+		return LogStatement{}, false
+	}
+
+	s := LogStatement{Path: f.Path, Line: f.Line(call.Pos()), EndLine: f.Line(call.End()), Level: level, Dynamic: true}
+	// The message is the first string literal, after any context
+	for _, arg := range call.Args {
+		if lit, ok := arg.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if msg, err := strconv.Unquote(lit.Value); err == nil {
+				s.Message, s.Dynamic = msg, false
+			}
+			break
+		}
+	}
+	return s, true
+}
+
+// Returns every logging call in the Go file.
+func GoLogStatements(f *File) []LogStatement {
+	statements := []LogStatement{}
+	for _, fn := range f.Funcs() {
+		statements = append(statements, fn.LogStatements()...)
+	}
+	return statements
+}
+
+// Returns every logging call in the function.
+func (fn Func) LogStatements() []LogStatement {
+	statements := []LogStatement{}
+	ast.Inspect(fn.Decl.Body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if s, ok := goLogCall(fn.File, call); ok {
+				s.Function = fn.String()
+				statements = append(statements, s)
+			}
+		}
+		return true
+	})
+	return statements
+}
+
+// Returns the logging calls that run after the line when the function
+// carries on normally, without taking any branch or returning early.
+// Execution that got past the line but never logged these likely
+// failed or returned somewhere in between.
+func (fn Func) LogsAfter(line int) []LogStatement {
+	following, _ := fn.after(fn.Decl.Body.List, line)
+	statements := []LogStatement{}
+	for _, st := range following {
+		expr, ok := st.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		if s, ok := goLogCall(fn.File, call); ok && s.Line != line {
+			s.Function = fn.String()
+			statements = append(statements, s)
+		}
+	}
+	return statements
+}
+
+// Returns the statements of the block that run after the line, and
+// whether execution carries on past the end of the block.
+func (fn Func) after(stmts []ast.Stmt, line int) ([]ast.Stmt, bool) {
+	for i, st := range stmts {
+		if fn.File.Line(st.Pos()) > line || fn.File.Line(st.End()) < line {
+			continue
+		}
+		following, falls := fn.nestedAfter(st, line)
+		if !falls {
+			return following, false
+		}
+		for _, rest := range stmts[i+1:] {
+			following = append(following, rest)
+			if terminates(rest) {
+				return following, false
+			}
+		}
+		return following, true
+	}
+	return nil, true
+}
+
+// Returns the statements nested in the statement that run after the line.
+func (fn Func) nestedAfter(st ast.Stmt, line int) ([]ast.Stmt, bool) {
+	in := func(n ast.Node) bool {
+		return n != nil && fn.File.Line(n.Pos()) <= line && line <= fn.File.Line(n.End())
+	}
+	switch s := st.(type) {
+	case *ast.IfStmt:
+		if in(s.Body) {
+			return fn.after(s.Body.List, line)
+		}
+		if in(s.Else) {
+			return fn.nestedAfter(s.Else, line)
+		}
+	case *ast.ForStmt:
+		return fn.leaveBlock(s.Body.List, line)
+	case *ast.RangeStmt:
+		return fn.leaveBlock(s.Body.List, line)
+	case *ast.SwitchStmt:
+		return fn.clauseAfter(s.Body.List, line)
+	case *ast.TypeSwitchStmt:
+		return fn.clauseAfter(s.Body.List, line)
+	case *ast.SelectStmt:
+		return fn.clauseAfter(s.Body.List, line)
+	case *ast.BlockStmt:
+		return fn.after(s.List, line)
+	case *ast.LabeledStmt:
+		return fn.nestedAfter(s.Stmt, line)
+	case *ast.ReturnStmt:
+		return nil, false
+	}
+	return nil, true
+}
+
+func (fn Func) clauseAfter(clauses []ast.Stmt, line int) ([]ast.Stmt, bool) {
+	for _, c := range clauses {
+		if fn.File.Line(c.Pos()) <= line && line <= fn.File.Line(c.End()) {
+			return fn.leaveBlock(clauseBody(c), line)
+		}
+	}
+	return nil, true
+}
+
+// Returns the statements after the line in the body of a loop or switch.
+// Breaking out or continuing the loop still carries on after it.
+func (fn Func) leaveBlock(stmts []ast.Stmt, line int) ([]ast.Stmt, bool) {
+	following, falls := fn.after(stmts, line)
+	if !falls && len(following) > 0 {
+		if b, ok := following[len(following)-1].(*ast.BranchStmt); ok && b.Label == nil && (b.Tok == token.BREAK || b.Tok == token.CONTINUE) {
+			return following[:len(following)-1], true
+		}
+	}
+	return following, falls
+}
+
+// Reports whether the level is debug or trace, levels usually turned off in production.
+func IsVerbose(level string) bool {
+	return strings.EqualFold(level, "debug") || strings.EqualFold(level, "trace")
+}
@@ -0,0 +1,244 @@
+package analysis
+
+import (
+	"go/ast"
+)
+
+// Lines of a function between two of its statements, split into the
+// lines that must have run and the branches that could not have.
+type ExecutionPath struct {
+	Ran     map[int]bool
+	Skipped map[int]bool
+}
+
+// Works out which lines of the function ran between the statements
+// on the from and to lines, like two log calls of the same request.
+// Branches of an if or switch that hold neither line are left alone,
+// unless they end in a return or similar and so can't have been taken.
+// Reports false when the lines aren't both in the function.
+func (f Func) PathBetween(from, to int) (ExecutionPath, bool) {
+	p := ExecutionPath{Ran: map[int]bool{}, Skipped: map[int]bool{}}
+	if from < f.Start || to > f.End || to < f.Start || from > f.End {
+		return p, false
+	}
+
+	if to < from {
+		// Only a loop gets from a later line back to an earlier one, run
+		// the rest of the loop body, then from its start to the to line
+		loop := f.enclosingLoop(from, to)
+		if loop == nil {
+			return p, false
+		}
+		start, end := f.File.Line(loop.Pos()), f.File.Line(loop.End())
+		(&pathFinder{f: f.File, p: p, from: from, to: end}).block(loopBody(loop).List)
+		p.Ran[start] = true
+		(&pathFinder{f: f.File, p: p, from: start, to: to}).block(loopBody(loop).List)
+		return p, true
+	}
+
+	(&pathFinder{f: f.File, p: p, from: from, to: to}).block(f.Decl.Body.List)
+	return p, true
+}
+
+type pathFinder struct {
+	f        *File
+	p        ExecutionPath
+	from, to int
+}
+
+func (pf *pathFinder) lines(n ast.Node) (int, int) {
+	return pf.f.Line(n.Pos()), pf.f.Line(n.End())
+}
+
+func (pf *pathFinder) contains(n ast.Node, line int) bool {
+	if n == nil {
+		return false
+	}
+	start, end := pf.lines(n)
+	return start <= line && line <= end
+}
+
+// Marks the lines of the node inside the from and to lines.
+func (pf *pathFinder) mark(set map[int]bool, n ast.Node) {
+	start, end := pf.lines(n)
+	for l := max(start, pf.from); l <= min(end, pf.to); l++ {
+		set[l] = true
+	}
+}
+
+func (pf *pathFinder) markAll(set map[int]bool, n ast.Node) {
+	start, end := pf.lines(n)
+	for l := start; l <= end; l++ {
+		set[l] = true
+	}
+}
+
+// Marks the line the statement starts on when it runs between the lines.
+func (pf *pathFinder) header(n ast.Node) {
+	if start, _ := pf.lines(n); start >= pf.from && start <= pf.to {
+		pf.p.Ran[start] = true
+	}
+}
+
+func (pf *pathFinder) block(stmts []ast.Stmt) {
+	for _, st := range stmts {
+		start, end := pf.lines(st)
+		if end < pf.from || start > pf.to {
+			continue
+		}
+		hasFrom, hasTo := pf.contains(st, pf.from), pf.contains(st, pf.to)
+
+		switch s := st.(type) {
+		case *ast.IfStmt:
+			pf.ifStmt(s, hasFrom, hasTo)
+		case *ast.SwitchStmt:
+			pf.header(s)
+			pf.clauses(s.Body.List, hasFrom, hasTo)
+		case *ast.TypeSwitchStmt:
+			pf.header(s)
+			pf.clauses(s.Body.List, hasFrom, hasTo)
+		case *ast.SelectStmt:
+			pf.header(s)
+			pf.clauses(s.Body.List, hasFrom, hasTo)
+		case *ast.ForStmt, *ast.RangeStmt:
+			pf.header(s)
+			if hasFrom || hasTo {
+				pf.block(loopBody(s).List)
+			}
+		case *ast.BlockStmt:
+			pf.block(s.List)
+		case *ast.LabeledStmt:
+			pf.block([]ast.Stmt{s.Stmt})
+		default:
+			pf.mark(pf.p.Ran, s)
+		}
+	}
+}
+
+func (pf *pathFinder) ifStmt(s *ast.IfStmt, hasFrom, hasTo bool) {
+	// The condition runs unless the path starts inside one of the branches
+	if !pf.contains(s.Body, pf.from) && !pf.contains(s.Else, pf.from) {
+		pf.header(s)
+	}
+
+	inThen := pf.contains(s.Body, pf.from) || pf.contains(s.Body, pf.to)
+	inElse := pf.contains(s.Else, pf.from) || pf.contains(s.Else, pf.to)
+	switch {
+	case inThen:
+		pf.block(s.Body.List)
+		if s.Else != nil {
+			pf.markAll(pf.p.Skipped, s.Else)
+		}
+	case inElse:
+		pf.markAll(pf.p.Skipped, s.Body)
+		pf.branch(s.Else)
+	case !hasFrom && !hasTo:
+		// Execution went past the if, so a branch that
+		// returns can't have been the one taken
+		thenEnds, elseEnds := terminates(s.Body), s.Else != nil && terminates(s.Else)
+		if thenEnds && !elseEnds {
+			pf.markAll(pf.p.Skipped, s.Body)
+			if s.Else != nil {
+				pf.branch(s.Else)
+			}
+		} else if elseEnds && !thenEnds {
+			pf.markAll(pf.p.Skipped, s.Else)
+			pf.block(s.Body.List)
+		}
+	}
+}
+
+func (pf *pathFinder) branch(s ast.Stmt) {
+	switch e := s.(type) {
+	case *ast.BlockStmt:
+		pf.block(e.List)
+	case *ast.IfStmt:
+		pf.ifStmt(e, pf.contains(e, pf.from), pf.contains(e, pf.to))
+	}
+}
+
+func (pf *pathFinder) clauses(clauses []ast.Stmt, hasFrom, hasTo bool) {
+	taken := -1
+	for i, c := range clauses {
+		if pf.contains(c, pf.from) || pf.contains(c, pf.to) {
+			taken = i
+		}
+	}
+
+	for i, c := range clauses {
+		body := clauseBody(c)
+		switch {
+		case taken >= 0 && i == taken:
+			pf.header(c)
+			pf.block(body)
+		case taken >= 0:
+			pf.markAll(pf.p.Skipped, c)
+		case !hasFrom && !hasTo && len(body) > 0 && terminates(&ast.BlockStmt{List: body}):
+			pf.markAll(pf.p.Skipped, c)
+		}
+	}
+}
+
+func clauseBody(c ast.Stmt) []ast.Stmt {
+	switch cc := c.(type) {
+	case *ast.CaseClause:
+		return cc.Body
+	case *ast.CommClause:
+		return cc.Body
+	}
+	return nil
+}
+
+func loopBody(s ast.Stmt) *ast.BlockStmt {
+	switch l := s.(type) {
+	case *ast.ForStmt:
+		return l.Body
+	case *ast.RangeStmt:
+		return l.Body
+	}
+	return &ast.BlockStmt{}
+}
+
+// Finds the innermost loop of the function holding both lines.
+func (f Func) enclosingLoop(a, b int) ast.Stmt {
+	var loop ast.Stmt
+	ast.Inspect(f.Decl.Body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			start, end := f.File.Line(n.Pos()), f.File.Line(n.End())
+			if start <= min(a, b) && max(a, b) <= end {
+				loop = n.(ast.Stmt)
+			}
+		}
+		return true
+	})
+	return loop
+}
+
+// Functions that never return to their caller.
+var exits = map[string]bool{"panic": true, "Exit": true, "Fatal": true, "Fatalf": true, "Fatalln": true, "Panic": true, "Panicf": true, "Panicln": true}
+
+// Reports whether execution can't continue past the end of the statement,
+// because it returns, breaks out, or exits the program.
+func terminates(s ast.Stmt) bool {
+	switch st := s.(type) {
+	case *ast.BlockStmt:
+		return len(st.List) > 0 && terminates(st.List[len(st.List)-1])
+	case *ast.ReturnStmt, *ast.BranchStmt:
+		return true
+	case *ast.IfStmt:
+		return st.Else != nil && terminates(st.Body) && terminates(st.Else)
+	case *ast.ExprStmt:
+		call, ok := st.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			return fun.Name == "panic"
+		case *ast.SelectorExpr:
+			return exits[fun.Sel.Name]
+		}
+	}
+	return false
+}
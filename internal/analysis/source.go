@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The files of a source root. Roots that aren't directories on disk,
+// like git revisions and release archives, are read through their own.
+type Source interface {
+	// Lists every file under the root.
+	Files() []string
+	// Reads a listed file.
+	Read(path string) (string, error)
+}
+
+// A source root that is a directory on disk.
+type Dir string
+
+func (d Dir) Files() []string {
+	root := string(d)
+	if root == "" {
+		root = "."
+	}
+	files := []string{}
+	filepath.WalkDir(root, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if e.IsDir() {
+			if p != root && (skipDirs[e.Name()] || strings.HasPrefix(e.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, p)
+		return nil
+	})
+	return files
+}
+
+func (d Dir) Read(path string) (string, error) {
+	src, err := os.ReadFile(path)
+	return string(src), err
+}
+
+// Reports whether the file under the root is in a directory that never
+// holds the source being analyzed, or in one of the extra directories.
+func skippedFile(root string, path string, extra ...string) bool {
+	rel := path
+	if root != "." && root != "" {
+		if r, ok := strings.CutPrefix(path, root); ok {
+			rel = r
+		} else {
+			rel = strings.TrimPrefix(path, filepath.Clean(root))
+		}
+	}
+	rel = filepath.ToSlash(rel)
+	dirs := strings.Split(strings.Trim(rel, "/"), "/")
+	for _, d := range dirs[:len(dirs)-1] {
+		if skipDirs[d] || strings.HasPrefix(d, ".") && d != "." && d != ".." {
+			return true
+		}
+		for _, e := range extra {
+			if d == e {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package analysis
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// A function found by scanning the text of a file in a language
+// without a parser here, using its braces or indentation.
+type TextFunc struct {
+	Name  string
+	Type  string // Class, struct or impl the function is declared in
+	Start int
+	End   int
+	Body  []string // Lines between the declaration and the end of the function
+}
+
+var (
+	textFuncDecls = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*(?:async\s+)?def\s+(\w+[?!]?)`), // Python, Ruby
+		regexp.MustCompile(`\bfn\s+(\w+)`),                      // Rust
+		regexp.MustCompile(`\bfunction\s+(\w+)\s*\(`),           // JavaScript, PHP
+		regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+(\w+)\s*=\s*(?:async\s*)?(?:function\b|\(?[\w\s,]*\)?\s*=>)`),                                                                    // Arrow functions
+		regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|final|override|suspend|async|synchronized|abstract|internal|open|virtual)\s+)*(?:fun\s+)?[\w<>\[\],.?]+\s+(\w+)\s*\([^;]*$`), // Java, Kotlin, C#
+	}
+	textTypeDecl = regexp.MustCompile(`\b(?:class|struct|impl(?:<[^>]*>)?|interface|trait|enum|object|module)\s+(?:\w+\s+for\s+)?(\w+)`)
+	// Words that look like a return type followed by a call
+	notFuncNames = map[string]bool{"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true, "new": true, "else": true, "throw": true, "await": true}
+)
+
+// Finds the function holding the line of a non-Go source file.
+func EnclosingTextFunc(path string, src string, line int) (TextFunc, bool) {
+	lines := strings.Split(src, "\n")
+	if line < 1 || line > len(lines) {
+		return TextFunc{}, false
+	}
+	indented := indentBlocks(path)
+
+	for start := line; start >= 1; start-- {
+		name := textFuncName(lines[start-1])
+		if name == "" {
+			continue
+		}
+		end := blockEnd(lines, start, indented)
+		if end < line {
+			// A function that ended before the line, keep looking outward
+			continue
+		}
+		// Brace blocks end on the closing brace, indented ones on their last statement
+		body := lines[start:max(end-1, start)]
+		if indented {
+			body = lines[start:end]
+		}
+		fn := TextFunc{Name: name, Start: start, End: end, Body: body}
+		fn.Type = enclosingType(lines, start, indented)
+		return fn, true
+	}
+	return TextFunc{}, false
+}
+
+func textFuncName(line string) string {
+	for _, p := range textFuncDecls {
+		if m := p.FindStringSubmatch(line); m != nil && !notFuncNames[m[1]] {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// Python and Ruby blocks are found by their indentation, everything else by braces.
+func indentBlocks(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".py" || ext == ".rb"
+}
+
+// Returns the last line of the block opened on the start line.
+func blockEnd(lines []string, start int, indented bool) int {
+	if indented {
+		indent := indentation(lines[start-1])
+		end := start
+		for i := start; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "" {
+				continue
+			}
+			if indentation(lines[i]) <= indent {
+				// Ruby closes its blocks with end
+				if strings.TrimSpace(lines[i]) == "end" {
+					end = i + 1
+				}
+				break
+			}
+			end = i + 1
+		}
+		return end
+	}
+
+	depth, opened := 0, false
+	for i := start - 1; i < len(lines); i++ {
+		for _, c := range stripStrings(lines[i]) {
+			switch c {
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+			}
+		}
+		if opened && depth <= 0 {
+			return i + 1
+		}
+		// A declaration without a body, like an interface method
+		if !opened && strings.HasSuffix(strings.TrimSpace(lines[i]), ";") {
+			return i + 1
+		}
+	}
+	return len(lines)
+}
+
+// Finds the class or impl the declaration on the start line is in.
+func enclosingType(lines []string, start int, indented bool) string {
+	for i := start - 1; i >= 1; i-- {
+		m := textTypeDecl.FindStringSubmatch(lines[i-1])
+		if m == nil {
+			continue
+		}
+		if blockEnd(lines, i, indented) >= start {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+var quoted = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`)
+
+// Removes string literals so braces inside messages aren't counted.
+func stripStrings(line string) string {
+	return quoted.ReplaceAllString(line, `""`)
+}
+
+// Reports whether the function does nothing but log. The body may
+// only hold a single statement besides comments and a bare return.
+func (f TextFunc) IsWrapper() bool {
+	statements := 0
+	for _, l := range f.Body {
+		t := strings.TrimSpace(l)
+		if t == "" || t == "}" || t == "end" || t == "return" || t == "return;" ||
+			strings.HasPrefix(t, "//") || strings.HasPrefix(t, "#") {
+			continue
+		}
+		statements++
+	}
+	return statements == 1
+}
+
+var textPackageDecl = regexp.MustCompile(`(?m)^\s*(?:package|namespace)\s+([\w.\\]+)`)
+
+// Returns the package or namespace declared by a Java, Kotlin, C#
+// or PHP file, or "" when the file doesn't declare one.
+func TextPackage(src string) string {
+	if m := textPackageDecl.FindStringSubmatch(src); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,241 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A commit as shown next to a line of source.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Summary string    `json:"summary"`
+}
+
+// Returns the abbreviated hash of the commit.
+func (c Commit) Short() string {
+	return c.Hash[:min(len(c.Hash), 8)]
+}
+
+// Hash git blame gives lines that haven't been committed.
+const uncommitted = "0000000000000000000000000000000000000000"
+
+// Reports whether the commit is the working tree rather than a real commit.
+func (c Commit) Uncommitted() bool {
+	return c.Hash == uncommitted
+}
+
+// Who last changed a line, and the commits that changed it before.
+type Blame struct {
+	Path    string   `json:"path"`
+	Line    int      `json:"line"`
+	Commit  Commit   `json:"commit"`
+	History []Commit `json:"history"` // Newest first, starting with Commit
+}
+
+// Commits listed in a line's history.
+const maxHistory = 10
+
+var (
+	cacheMu      sync.Mutex
+	blameCache   = map[string][]Commit{} // Commit of every line of a file at a revision
+	historyCache = map[string][]Commit{}
+)
+
+// Runs git in the directory and returns its output.
+func run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok && len(exit.Stderr) > 0 {
+			return "", fmt.Errorf("error running git %s: %s", args[0], strings.TrimSpace(string(exit.Stderr)))
+		}
+		return "", fmt.Errorf("error running git %s: %v", args[0], err)
+	}
+	return string(out), nil
+}
+
+// Returns the commit checked out in the repository holding the directory.
+func head(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "HEAD")
+	return strings.TrimSpace(out), err
+}
+
+// Returns the top of the repository holding the path and the path
+// relative to it. The path need not exist, like a file whose directory
+// was deleted since an older revision, as long as a parent directory does.
+func RepoPath(path string) (string, string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", fmt.Errorf("error resolving %s: %v", path, err)
+	}
+	dir := filepath.Dir(abs)
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("error resolving %s: no parent directory exists", path)
+		}
+		dir = parent
+	}
+
+	top, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", "", err
+	}
+	top = strings.TrimSpace(top)
+	// The top level has its symlinks resolved, like /tmp on macOS
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		abs = filepath.Join(real, strings.TrimPrefix(abs, dir))
+	}
+	rel, err := filepath.Rel(top, abs)
+	if err != nil {
+		return "", "", fmt.Errorf("error resolving %s: %v", path, err)
+	}
+	return top, filepath.ToSlash(rel), nil
+}
+
+// Blames the line of the file with git blame and git log -L. Results
+// are cached per file and checked out commit, so the file is blamed once
+// until something is committed or the file is edited.
+func BlameLine(path string, line int) (Blame, error) {
+	return BlameLineAt("", path, line)
+}
+
+// Blames the line of the file as it was at the revision, or in the
+// working tree when the revision is empty.
+func BlameLineAt(at string, path string, line int) (Blame, error) {
+	// Git runs from the top of the repository since the file's own
+	// directory may not exist anymore when blaming an older revision
+	dir, name, err := RepoPath(path)
+	if err != nil {
+		return Blame{}, err
+	}
+	abs, _ := filepath.Abs(path)
+	key := abs + "@" + at
+	revArgs := []string{}
+	if at != "" {
+		revArgs = append(revArgs, at)
+	} else {
+		rev, err := head(dir)
+		if err != nil {
+			return Blame{}, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return Blame{}, fmt.Errorf("error reading %s: %v", path, err)
+		}
+		// Edits that aren't committed yet change the blame too
+		key = fmt.Sprintf("%s@%s@%d", abs, rev, info.ModTime().UnixNano())
+	}
+
+	cacheMu.Lock()
+	lines, found := blameCache[key]
+	cacheMu.Unlock()
+	if !found {
+		out, err := run(dir, append(append([]string{"blame", "--porcelain"}, revArgs...), "--", name)...)
+		if err != nil {
+			return Blame{}, err
+		}
+		lines = parsePorcelain(out)
+		cacheMu.Lock()
+		blameCache[key] = lines
+		cacheMu.Unlock()
+	}
+	if line < 1 || line > len(lines) {
+		return Blame{}, fmt.Errorf("line %d is outside %s", line, path)
+	}
+
+	historyKey := fmt.Sprintf("%s:%d", key, line)
+	cacheMu.Lock()
+	history, found := historyCache[historyKey]
+	cacheMu.Unlock()
+	if !found {
+		args := append([]string{"log", "-L", fmt.Sprintf("%d,%d:%s", line, line, name), "-s", "-n", strconv.Itoa(maxHistory), "--format=%H%x00%an%x00%ae%x00%at%x00%s"}, revArgs...)
+		out, err := run(dir, args...)
+		if err != nil {
+			return Blame{}, err
+		}
+		history = parseLog(out)
+		cacheMu.Lock()
+		historyCache[historyKey] = history
+		cacheMu.Unlock()
+	}
+
+	return Blame{Path: path, Line: line, Commit: lines[line-1], History: history}, nil
+}
+
+// Reads the commit of every line from git blame --porcelain. Details of
+// a commit are only given the first time it appears.
+func parsePorcelain(out string) []Commit {
+	commits := map[string]*Commit{}
+	lines := []Commit{}
+	var current *Commit
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if strings.HasPrefix(text, "\t") {
+			// The line's content ends each entry
+			if current != nil {
+				lines = append(lines, *current)
+			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(text, " ")
+		// Each entry starts with the hash of its commit
+		if len(key) == 40 && strings.Trim(key, "0123456789abcdef") == "" {
+			if commits[key] == nil {
+				commits[key] = &Commit{Hash: key}
+			}
+			current = commits[key]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-mail":
+			current.Email = strings.Trim(value, "<>")
+		case "author-time":
+			if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.Date = time.Unix(ts, 0)
+			}
+		case "summary":
+			current.Summary = value
+		}
+	}
+	return lines
+}
+
+// Reads commits written with --format=%H%x00%an%x00%ae%x00%at%x00%s.
+func parseLog(out string) []Commit {
+	commits := []Commit{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 5 {
+			continue
+		}
+		c := Commit{Hash: fields[0], Author: fields[1], Email: fields[2], Summary: fields[4]}
+		if ts, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			c.Date = time.Unix(ts, 0)
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
@@ -0,0 +1,93 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Creates a repository and commits each version of main.go in turn.
+func testRepo(t *testing.T, versions ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	gitRun := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	gitRun("init", "-q")
+	for i, src := range versions {
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		gitRun("add", "main.go")
+		gitRun("commit", "-q", "-m", []string{"Add login", "Reword login log", "Add logout"}[i])
+	}
+	return dir
+}
+
+func TestBlameLine(t *testing.T) {
+	dir := testRepo(t,
+		"package main\n\nfunc login() {\n\tlog.Info(\"login\")\n}\n",
+		"package main\n\nfunc login() {\n\tlog.Info(\"User logged in\")\n}\n",
+		"package main\n\nfunc login() {\n\tlog.Info(\"User logged in\")\n}\n\nfunc logout() {}\n",
+	)
+	path := filepath.Join(dir, "main.go")
+
+	b, err := BlameLine(path, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Commit.Summary != "Reword login log" || b.Commit.Author != "Jane Doe" || b.Commit.Email != "jane@example.com" || b.Commit.Date.IsZero() {
+		t.Errorf("Unexpected commit %+v", b.Commit)
+	}
+	if len(b.History) != 2 || b.History[0].Hash != b.Commit.Hash || b.History[1].Summary != "Add login" {
+		t.Errorf("Unexpected history %+v", b.History)
+	}
+
+	if b, err := BlameLine(path, 7); err != nil || b.Commit.Summary != "Add logout" {
+		t.Errorf("Unexpected blame of the last line %+v %v", b, err)
+	}
+	if _, err := BlameLine(path, 100); err == nil {
+		t.Error("Expected an error for a line outside the file")
+	}
+
+	// Lines that aren't committed have no author yet
+	os.WriteFile(path, []byte("package main\n\nfunc login() {\n\tlog.Info(\"User logged in\")\n\tlog.Info(\"new\")\n}\n"), 0644)
+	if b, err := BlameLine(path, 5); err != nil || !b.Commit.Uncommitted() {
+		t.Errorf("Expected an uncommitted line, got %+v %v", b, err)
+	}
+}
+
+func TestBlameDeletedDirectory(t *testing.T) {
+	dir := testRepo(t, "package main\n")
+	gitRun := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	path := filepath.Join(dir, "old", "pay.go")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("package old\n\nfunc charge() {}\n"), 0644)
+	gitRun("add", "old")
+	gitRun("commit", "-q", "-m", "Add payments")
+	rev := gitRun("rev-parse", "HEAD")
+	gitRun("rm", "-q", "-r", "old")
+	gitRun("commit", "-q", "-m", "Remove payments")
+
+	if top, rel, err := RepoPath(path); err != nil || rel != "old/pay.go" || top == "" {
+		t.Errorf("Unexpected repository path %s %s %v", top, rel, err)
+	}
+	if b, err := BlameLineAt(rev, path, 3); err != nil || b.Commit.Summary != "Add payments" {
+		t.Errorf("Unexpected blame of a deleted directory %+v %v", b, err)
+	}
+}
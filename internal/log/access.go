@@ -0,0 +1,98 @@
+package log
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterParser(accessParser{})
+}
+
+// Parses HTTP access logs in the common and combined formats used by
+// nginx and Apache. A trailing request time, as added by most custom
+// formats, is read as the latency.
+type accessParser struct{}
+
+var accessLine = regexp.MustCompile(`^(\S+) \S+ (\S+) \[([^\]]+)\] "([^"]*)" (\d{3}) (\S+)(?: "([^"]*)" "([^"]*)")?(.*)$`)
+
+func (accessParser) Name() string { return "access" }
+
+func (accessParser) Sniff(name string, head []string) float64 {
+	return sniffAll(head, 0.9, accessLine.MatchString)
+}
+
+func (accessParser) Parse(file io.Reader) ([]Log, error) {
+	return parseLines(file, parseAccessLine)
+}
+
+func parseAccessLine(line string) (Log, bool) {
+	m := accessLine.FindStringSubmatch(line)
+	if m == nil {
+		return Log{}, false
+	}
+
+	l := Log{Message: m[4], Attributes: map[string]string{}, Sources: []SourceMapping{}}
+	if t, err := time.Parse("02/Jan/2006:15:04:05 -0700", m[3]); err == nil {
+		l.Time = t
+	}
+	l.Attributes["remote_addr"] = m[1]
+	if m[2] != "-" {
+		l.Attributes["remote_user"] = m[2]
+	}
+
+	// The request line is METHOD PATH PROTOCOL
+	if parts := strings.Fields(m[4]); len(parts) >= 2 {
+		l.Attributes["method"] = parts[0]
+		l.Attributes["path"] = parts[1]
+	}
+
+	l.Attributes["status"] = m[5]
+	status, _ := strconv.Atoi(m[5])
+	switch {
+	case status >= 500:
+		l.Level = "error"
+	case status >= 400:
+		l.Level = "warning"
+	default:
+		l.Level = "info"
+	}
+
+	if m[6] != "-" {
+		l.Attributes["bytes"] = m[6]
+	}
+	if m[7] != "" && m[7] != "-" {
+		l.Attributes["referer"] = m[7]
+	}
+	if m[8] != "" && m[8] != "-" {
+		l.Attributes["user_agent"] = m[8]
+	}
+	if latency, ok := parseLatency(m[9]); ok {
+		l.Attributes["latency"] = latency.String()
+	}
+	return l, true
+}
+
+// Reads the first number after the standard fields as the request time.
+// nginx's $request_time is in seconds with a fraction while Apache's %D
+// is a whole number of microseconds.
+func parseLatency(rest string) (time.Duration, bool) {
+	for _, f := range strings.Fields(rest) {
+		// Custom formats often label the value, rt=0.123
+		if _, v, found := strings.Cut(f, "="); found {
+			f = v
+		}
+		f = strings.Trim(f, `"`)
+		if strings.Contains(f, ".") {
+			if s, err := strconv.ParseFloat(f, 64); err == nil {
+				return time.Duration(s * float64(time.Second)), true
+			}
+		} else if us, err := strconv.Atoi(f); err == nil {
+			return time.Duration(us) * time.Microsecond, true
+		}
+	}
+	return 0, false
+}
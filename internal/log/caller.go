@@ -0,0 +1,137 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Files under each source root, used to resolve caller paths
+// that were recorded on a build machine.
+var fileListCache = map[string][]string{}
+
+// Directories that never contain the source we are looking for.
+var skipDirs = map[string]bool{".git": true, "node_modules": true, "target": true}
+
+// Maps a caller recorded by the logger (path/to/file.go:42) to the source
+// it points at. The recorded path is usually relative to a different
+// checkout so it is matched by its longest suffix found under the root.
+func callerSources(caller string, root string, function string) []SourceMapping {
+	path, line := caller, 1
+	if i := strings.LastIndex(caller, ":"); i > 0 {
+		if n, err := strconv.Atoi(caller[i+1:]); err == nil {
+			path, line = caller[:i], n
+		}
+	}
+	if path == "" {
+		return nil
+	}
+
+	msg := "Source location recorded by the logger"
+	if function != "" {
+		msg += " in " + function
+	}
+
+	sources := []SourceMapping{}
+	for _, p := range resolveCallerPath(path, root) {
+		source, err := readSource(p)
+		if err != nil {
+			continue
+		}
+		// The recorded line may not exist in an older or newer checkout
+		lineCount := strings.Count(source, "\n")
+		sources = append(sources, SourceMapping{
+			Path:           p,
+			Line:           max(min(line, lineCount), 1),
+			DisplayMessage: msg,
+			SourceCode:     source,
+		})
+	}
+	return sources
+}
+
+// Finds the files under root that a recorded caller path refers to.
+func resolveCallerPath(path string, root string) []string {
+	path = filepath.ToSlash(filepath.Clean(path))
+	if root == "" {
+		root = "."
+	}
+
+	if !filepath.IsAbs(path) {
+		if p := filepath.Join(root, path); fileExists(p) {
+			return []string{p}
+		}
+	}
+	if fileExists(path) && (filepath.IsAbs(path) || root == ".") {
+		return []string{path}
+	}
+
+	files, ok := fileListCache[root]
+	if !ok {
+		files = listFiles(root)
+		fileListCache[root] = files
+	}
+
+	matching := func(suffix string) []string {
+		matches := []string{}
+		for _, f := range files {
+			if strings.HasSuffix("/"+filepath.ToSlash(f), suffix) {
+				matches = append(matches, f)
+			}
+		}
+		return matches
+	}
+
+	// Try the longest suffix first so pkg/a/log.go beats b/log.go. A
+	// file name alone would match every handler.go under the root, so
+	// suffixes keep at least one directory
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if matches := matching("/" + strings.Join(parts[i:], "/")); len(matches) > 0 {
+			return matches
+		}
+	}
+	// unless the logger only recorded the file name, which is then trusted when it is unique
+	if len(parts) == 1 {
+		if matches := matching("/" + parts[0]); len(matches) == 1 {
+			return matches
+		}
+	}
+	return nil
+}
+
+func listFiles(root string) []string {
+	return treeFor(root).files()
+}
+
+func fileExists(path string) bool {
+	if _, ok := treeOf(path).(dirTree); !ok {
+		_, err := readSource(path)
+		return err == nil
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// Prepends the direct sources to the searched ones, dropping placeholder
+// mappings and any source that points at a line already listed.
+func mergeSources(direct []SourceMapping, searched []SourceMapping) []SourceMapping {
+	merged := append([]SourceMapping{}, direct...)
+	for _, s := range searched {
+		if s.Path == "" {
+			continue
+		}
+		duplicate := false
+		for _, d := range merged {
+			if filepath.Clean(d.Path) == filepath.Clean(s.Path) && d.Line == s.Line {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
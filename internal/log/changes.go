@@ -0,0 +1,120 @@
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"vlsa/internal/analysis"
+	"vlsa/internal/bus"
+)
+
+// Lines of a file that were added or changed, inclusive.
+type lineRange struct{ start, end int }
+
+// Changed lines of each file under a root, keyed by root and revision.
+var changesCache = map[string]map[string][]lineRange{}
+
+// Hunk headers of a diff without context, @@ -12,3 +14,5 @@.
+var hunkHeader = regexp.MustCompile(`^@@ -\S+ \+(\d+)(?:,(\d+))? @@`)
+
+// Returns the lines of each file under the root that changed between
+// the revision and HEAD, as numbered in HEAD.
+func changedLines(since, root string) map[string][]lineRange {
+	key := cacheKey(root, since)
+	if changes, found := changesCache[key]; found {
+		return changes
+	}
+	dir := root
+	if dir == "" {
+		dir = "."
+	}
+
+	changes := map[string][]lineRange{}
+	rev := resolveRevision(root, since)
+	if rev == "" {
+		bus.LogChannel <- fmt.Sprintf("Error diffing %s: %s is not a commit", dir, since)
+		changesCache[key] = changes
+		return changes
+	}
+	out, err := exec.Command("git", "-C", dir, "diff", "-U0", "--no-color", "--no-ext-diff", "--relative", "--end-of-options", rev, "HEAD", "--", ".").Output()
+	if err != nil {
+		bus.LogChannel <- fmt.Sprintf("Error diffing %s against %s: %v", dir, since, err)
+	}
+
+	file := ""
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "+++ "); ok {
+			file = ""
+			if name != "/dev/null" {
+				file = filepath.Clean(filepath.Join(root, strings.TrimPrefix(name, "b/")))
+			}
+			continue
+		}
+		m := hunkHeader.FindStringSubmatch(line)
+		if m == nil || file == "" {
+			continue
+		}
+		start, _ := strconv.Atoi(m[1])
+		count := 1
+		if m[2] != "" {
+			count, _ = strconv.Atoi(m[2])
+		}
+		// Removed lines leave no lines behind, the line after them stands in
+		if count == 0 {
+			start, count = start+1, 1
+		}
+		changes[file] = append(changes[file], lineRange{start, start + count - 1})
+	}
+
+	changesCache[key] = changes
+	return changes
+}
+
+// Marks the sources whose line, or the function around it, changed
+// since the revision. Sources read from older revisions are left alone.
+func markChanged(sources []SourceMapping, since, root string) {
+	changes := changedLines(since, root)
+	for i := range sources {
+		s := &sources[i]
+		markChanged(s.Callers, since, root)
+		if rev, _ := SplitRevision(s.Path); rev != "" || s.Path == "" {
+			continue
+		}
+		ranges := changes[filepath.Clean(s.Path)]
+		if len(ranges) == 0 {
+			continue
+		}
+
+		start, end := s.Line, s.Line
+		if strings.HasSuffix(s.Path, ".go") {
+			if fn, ok := analysis.EnclosingFunc(s.Path, s.SourceCode, s.Line); ok {
+				start, end = fn.Start, fn.End
+			}
+		} else if fn, ok := analysis.EnclosingTextFunc(s.Path, s.SourceCode, s.Line); ok {
+			start, end = fn.Start, fn.End
+		}
+		for _, r := range ranges {
+			if r.start <= end && start <= r.end {
+				s.Changed = true
+				break
+			}
+		}
+	}
+}
+
+// Reports whether the log's selected source changed since the --since revision.
+func (l Log) Changed() bool {
+	i := l.SelectedSourceIdx
+	if i < 0 || i >= len(l.Sources) {
+		i = 0
+	}
+	return len(l.Sources) > 0 && l.Sources[i].Changed
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"vlsa/internal/bus"
+)
+
+func TestMarkChanged(t *testing.T) {
+	root, commit := testGitRepo(t)
+	old := commit("user.go", "package svc\n\nfunc create() {\n\tlog.Info(\"User created\")\n}\n\nfunc remove() {\n\tlog.Info(\"User removed\")\n\tcleanup()\n}\n")
+	src := "package svc\n\nfunc create() {\n\tlog.Info(\"User created\")\n}\n\nfunc remove() {\n\tlog.Info(\"User removed\")\n\tcleanup()\n\taudit()\n}\n"
+	commit("user.go", src)
+
+	path := filepath.Join(root, "user.go")
+	sources := []SourceMapping{
+		{Path: path, Line: 4, SourceCode: src},
+		// The log line is the same but its function changed
+		{Path: path, Line: 8, SourceCode: src},
+	}
+	markChanged(sources, old, root)
+	if sources[0].Changed || !sources[1].Changed {
+		t.Errorf("Expected only the source in remove to be changed, got %+v", sources)
+	}
+
+	l := Log{Sources: sources, SelectedSourceIdx: 1}
+	if !l.Changed() {
+		t.Error("Expected the log to be changed through its selected source")
+	}
+}
+
+func TestChangedLinesOptions(t *testing.T) {
+	root, commit := testGitRepo(t)
+	commit("user.go", "package svc\n")
+	done := make(chan bool)
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-bus.LogChannel:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// Revisions that look like options are rejected before git sees them
+	out := filepath.Join(t.TempDir(), "diff")
+	if changes := changedLines("--output="+out, root); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Errorf("Expected git not to write %s", out)
+	}
+}
@@ -0,0 +1,280 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterParser(dockerParser{})
+	RegisterParser(criParser{})
+	RegisterParser(kubectlParser{})
+}
+
+// Parses Docker's json-file logging driver output
+// ({"log":"message\n","stream":"stderr","time":"2024-..."}).
+type dockerParser struct{}
+
+type dockerLine struct {
+	Log    string            `json:"log"`
+	Stream string            `json:"stream"`
+	Time   string            `json:"time"`
+	Attrs  map[string]string `json:"attrs"`
+}
+
+func (dockerParser) Name() string { return "docker" }
+
+func (dockerParser) Sniff(name string, head []string) float64 {
+	return sniffAll(head, 0.95, func(line string) bool {
+		var obj map[string]any
+		if json.Unmarshal([]byte(line), &obj) != nil {
+			return false
+		}
+		_, hasLog := obj["log"]
+		_, hasStream := obj["stream"]
+		return hasLog && hasStream
+	})
+}
+
+func (dockerParser) Parse(file io.Reader) ([]Log, error) {
+	logs := []Log{}
+	emit := func(dl dockerLine, msg string, raw string) {
+		l := containerLog(msg, dl.Stream)
+		l.Raw = raw
+		if t, ok := parseTime(dl.Time); ok {
+			l.Time = t
+		}
+		// Containers started with --log-opt tag or labels record their name in attrs
+		for _, k := range []string{"io.kubernetes.container.name", "tag", "name"} {
+			if dl.Attrs[k] != "" {
+				l.Service = dl.Attrs[k]
+				break
+			}
+		}
+		for k, v := range dl.Attrs {
+			l.Attributes[k] = v
+		}
+		logs = append(logs, l)
+	}
+
+	partials := newPartialLines[dockerLine]()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var dl dockerLine
+		if err := json.Unmarshal(scanner.Bytes(), &dl); err != nil {
+			continue // Skip lines that are not JSON objects
+		}
+
+		// Docker splits long lines into 16KB chunks, only the last
+		// chunk of a line ends with a newline
+		if !strings.HasSuffix(dl.Log, "\n") {
+			partials.add(dl.Stream, dl.Log, scanner.Text(), dl)
+			continue
+		}
+		msg, raw := partials.join(dl.Stream, strings.TrimRight(dl.Log, "\r\n"), scanner.Text())
+		emit(dl, msg, raw)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading log file: %v", err)
+	}
+	partials.flush(emit)
+
+	return logs, nil
+}
+
+// Parses the CRI logging format written by containerd and CRI-O
+// (2024-06-19T03:39:21.231234567Z stdout F message).
+type criParser struct{}
+
+var criLine = regexp.MustCompile(`^(\S+) (stdout|stderr) ([PF]) ?(.*)$`)
+
+func (criParser) Name() string { return "cri" }
+
+func (criParser) Sniff(name string, head []string) float64 {
+	return sniffAll(head, 0.95, func(line string) bool {
+		m := criLine.FindStringSubmatch(line)
+		if m == nil {
+			return false
+		}
+		_, err := time.Parse(time.RFC3339Nano, m[1])
+		return err == nil
+	})
+}
+
+func (criParser) Parse(file io.Reader) ([]Log, error) {
+	logs := []Log{}
+	emit := func(m []string, msg string, raw string) {
+		l := containerLog(msg, m[2])
+		l.Raw = raw
+		if t, err := time.Parse(time.RFC3339Nano, m[1]); err == nil {
+			l.Time = t
+		}
+		logs = append(logs, l)
+	}
+
+	partials := newPartialLines[[]string]()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		m := criLine.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+
+		// P marks a partial line that continues in the next entry of its stream
+		if m[3] == "P" {
+			partials.add(m[2], m[4], scanner.Text(), m)
+			continue
+		}
+		msg, raw := partials.join(m[2], m[4], scanner.Text())
+		emit(m, msg, raw)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading log file: %v", err)
+	}
+	partials.flush(emit)
+
+	return logs, nil
+}
+
+// Parses the output of kubectl logs --prefix --timestamps
+// ([pod/identity-7d9f/identity] 2024-06-19T03:39:21.231234567Z message).
+type kubectlParser struct{}
+
+var kubectlLine = regexp.MustCompile(`^\[pod/([^/\]]+)/([^\]]+)\] (\S+) ?(.*)$`)
+
+func (kubectlParser) Name() string { return "kubectl" }
+
+func (kubectlParser) Sniff(name string, head []string) float64 {
+	return sniffAll(head, 0.95, kubectlLine.MatchString)
+}
+
+func (kubectlParser) Parse(file io.Reader) ([]Log, error) {
+	logs := []Log{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		m := kubectlLine.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+
+		// Without --timestamps the timestamp is part of the message
+		msg := m[3] + " " + m[4]
+		t, err := time.Parse(time.RFC3339Nano, m[3])
+		if err == nil {
+			msg = m[4]
+		}
+		l := containerLog(msg, "")
+		l.Raw = scanner.Text()
+		if err == nil {
+			l.Time = t
+		}
+		l.Service = m[2]
+		l.Attributes["pod"] = m[1]
+		logs = append(logs, l)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading log file: %v", err)
+	}
+
+	return logs, nil
+}
+
+// Chunks of the lines a container runtime split, kept per stream since
+// the chunks of stdout and stderr are interleaved. Chunk is the last
+// chunk read of each line, whose time the whole line is logged at.
+type partialLines[Chunk any] struct {
+	msgs    map[string]string
+	raws    map[string]string
+	last    map[string]Chunk
+	streams []string // Streams with a line in progress, in the order it started
+}
+
+func newPartialLines[Chunk any]() *partialLines[Chunk] {
+	return &partialLines[Chunk]{msgs: map[string]string{}, raws: map[string]string{}, last: map[string]Chunk{}}
+}
+
+// Adds a chunk to the line in progress on the stream.
+func (p *partialLines[Chunk]) add(stream string, msg string, raw string, chunk Chunk) {
+	if _, ok := p.msgs[stream]; !ok {
+		p.streams = append(p.streams, stream)
+	}
+	p.msgs[stream] += msg
+	p.raws[stream] += raw + "\n"
+	p.last[stream] = chunk
+}
+
+// Returns the stream's line the last chunk completes, and its raw lines.
+func (p *partialLines[Chunk]) join(stream string, msg string, raw string) (string, string) {
+	msg, raw = p.msgs[stream]+msg, p.raws[stream]+raw
+	delete(p.msgs, stream)
+	delete(p.raws, stream)
+	delete(p.last, stream)
+	for i, s := range p.streams {
+		if s == stream {
+			p.streams = append(p.streams[:i], p.streams[i+1:]...)
+			break
+		}
+	}
+	return msg, raw
+}
+
+// Emits the lines whose last chunk never came, like the line a
+// container was writing when the file was copied.
+func (p *partialLines[Chunk]) flush(emit func(chunk Chunk, msg string, raw string)) {
+	for _, stream := range append([]string{}, p.streams...) {
+		chunk := p.last[stream]
+		msg, raw := p.join(stream, "", "")
+		emit(chunk, strings.TrimRight(msg, "\r\n"), strings.TrimSuffix(raw, "\n"))
+	}
+}
+
+// Builds a log from a line a container wrote.
+func containerLog(msg string, stream string) Log {
+	l := Log{Message: msg, Attributes: map[string]string{}}
+	if inner, ok := parseEmbedded(msg); ok {
+		mergeEmbedded(&l, inner)
+	}
+
+	if stream != "" {
+		l.Attributes["stream"] = stream
+	}
+	l.Sources = []SourceMapping{}
+	return l
+}
+
+// Kubelet names container log files after the pod and container they belong to,
+// either /var/log/pods/<namespace>_<pod>_<uid>/<container>/0.log or
+// /var/log/containers/<pod>_<namespace>_<container>-<id>.log.
+// Returns the container name and pod, or empty strings for any other path.
+func containerFromPath(path string) (string, string) {
+	path = filepath.ToSlash(path)
+	dir, file := filepath.Split(path)
+
+	if i := strings.Index(dir, "/pods/"); i >= 0 {
+		parts := strings.Split(strings.Trim(dir[i+len("/pods/"):], "/"), "/")
+		if len(parts) == 2 {
+			if podParts := strings.Split(parts[0], "_"); len(podParts) == 3 {
+				return parts[1], podParts[1]
+			}
+		}
+	}
+
+	if strings.HasSuffix(dir, "/containers/") && strings.HasSuffix(file, ".log") {
+		parts := strings.Split(strings.TrimSuffix(file, ".log"), "_")
+		if len(parts) == 3 {
+			if i := strings.LastIndex(parts[2], "-"); i > 0 {
+				return parts[2][:i], parts[0]
+			}
+		}
+	}
+	return "", ""
+}
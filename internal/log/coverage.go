@@ -0,0 +1,170 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"vlsa/internal/analysis"
+)
+
+// A log statement and how many of the loaded logs it wrote.
+type StatementCoverage struct {
+	analysis.LogStatement
+	Hits int
+}
+
+// The log statements of a source file.
+type FileCoverage struct {
+	Path       string
+	Package    string // Directory the file is in
+	Statements []StatementCoverage
+	Hit        int // Statements seen at least once
+}
+
+// The log statements of every file in a directory.
+type PackageCoverage struct {
+	Package    string
+	Statements int
+	Hit        int
+}
+
+// Which log statements under the source roots were seen in the logs,
+// like a test coverage report where running is being logged.
+type CoverageReport struct {
+	Files      []FileCoverage
+	Packages   []PackageCoverage
+	Statements int
+	Hit        int
+}
+
+// Returns the percentage of statements that were hit.
+func percent(hit, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(hit) * 100 / float64(total)
+}
+
+func (f FileCoverage) Percent() float64    { return percent(f.Hit, len(f.Statements)) }
+func (p PackageCoverage) Percent() float64 { return percent(p.Hit, p.Statements) }
+func (r CoverageReport) Percent() float64  { return percent(r.Hit, r.Statements) }
+
+// Counts how often each log statement under the mapped source roots
+// wrote one of the logs. Each log counts once, towards the first of
+// its sources, starting from the selected one, that is a log statement.
+func Coverage(logs []Log) CoverageReport {
+	roots := mappedRootList()
+
+	files := map[string]*FileCoverage{}
+	seen := map[string]bool{}
+	for _, root := range roots {
+		for _, s := range analysis.LogStatements(analysisSource(root)) {
+			path := filepath.Clean(s.Path)
+			key := fmt.Sprintf("%s:%d", path, s.Line)
+			if seen[key] {
+				continue // Roots can overlap
+			}
+			seen[key] = true
+			if files[path] == nil {
+				files[path] = &FileCoverage{Path: path, Package: filepath.Dir(path)}
+			}
+			files[path].Statements = append(files[path].Statements, StatementCoverage{LogStatement: s})
+		}
+	}
+
+	for _, l := range logs {
+		for _, s := range coverageOrder(l) {
+			if st := statementAt(files[filepath.Clean(s.Path)], s.Line); st != nil {
+				st.Hits++
+				break
+			}
+		}
+	}
+
+	report := CoverageReport{}
+	packages := map[string]*PackageCoverage{}
+	for _, f := range files {
+		for _, s := range f.Statements {
+			if s.Hits > 0 {
+				f.Hit++
+			}
+		}
+		report.Files = append(report.Files, *f)
+		report.Statements += len(f.Statements)
+		report.Hit += f.Hit
+
+		if packages[f.Package] == nil {
+			packages[f.Package] = &PackageCoverage{Package: f.Package}
+		}
+		packages[f.Package].Statements += len(f.Statements)
+		packages[f.Package].Hit += f.Hit
+	}
+	for _, p := range packages {
+		report.Packages = append(report.Packages, *p)
+	}
+	sort.Slice(report.Files, func(i, j int) bool { return report.Files[i].Path < report.Files[j].Path })
+	sort.Slice(report.Packages, func(i, j int) bool { return report.Packages[i].Package < report.Packages[j].Package })
+	return report
+}
+
+// Returns the log's sources with the selected one first.
+func coverageOrder(l Log) []SourceMapping {
+	if l.SelectedSourceIdx <= 0 || l.SelectedSourceIdx >= len(l.Sources) {
+		return l.Sources
+	}
+	sources := []SourceMapping{l.Sources[l.SelectedSourceIdx]}
+	sources = append(sources, l.Sources[:l.SelectedSourceIdx]...)
+	return append(sources, l.Sources[l.SelectedSourceIdx+1:]...)
+}
+
+// Returns the statement of the file that covers the line. Calls spanning
+// several lines are matched by any of them since the message searched
+// for is often on a line after the call.
+func statementAt(f *FileCoverage, line int) *StatementCoverage {
+	if f == nil {
+		return nil
+	}
+	for i := range f.Statements {
+		if s := &f.Statements[i]; s.Line <= line && line <= max(s.Line, s.EndLine) {
+			return s
+		}
+	}
+	return nil
+}
+
+// Writes the coverage of every package and file, followed by the
+// statements that never logged anything.
+func (r CoverageReport) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "%-60s %10s %7s\n", "PACKAGE", "LOGGED", "")
+	for _, p := range r.Packages {
+		fmt.Fprintf(w, "%-60s %4d/%-5d %6.1f%%\n", p.Package, p.Hit, p.Statements, p.Percent())
+	}
+	fmt.Fprintf(w, "\n%-60s %10s %7s\n", "FILE", "LOGGED", "")
+	for _, f := range r.Files {
+		fmt.Fprintf(w, "%-60s %4d/%-5d %6.1f%%\n", f.Path, f.Hit, len(f.Statements), f.Percent())
+	}
+
+	never := []string{}
+	for _, f := range r.Files {
+		for _, s := range f.Statements {
+			if s.Hits == 0 {
+				never = append(never, fmt.Sprintf("%s:%d\t%s\t%s", s.Path, s.Line, s.Level, describeStatement(s.LogStatement)))
+			}
+		}
+	}
+	if len(never) > 0 {
+		fmt.Fprintf(w, "\nNever logged:\n%s\n", strings.Join(never, "\n"))
+	}
+	fmt.Fprintf(w, "\nTotal: %d of %d log statements seen (%.1f%%)\n", r.Hit, r.Statements, r.Percent())
+}
+
+// Returns the message of the statement, or a placeholder when it is built at runtime.
+func describeStatement(s analysis.LogStatement) string {
+	if s.Message == "" {
+		return "(dynamic message)"
+	}
+	return fmt.Sprintf("%q", s.Message)
+}
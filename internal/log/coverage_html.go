@@ -0,0 +1,109 @@
+package log
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// A line of source in the HTML report.
+type coverageLine struct {
+	Number int
+	Text   string
+	Class  string // hit or miss on log statements
+	Title  string
+}
+
+type coverageFile struct {
+	ID    int
+	Path  string
+	Title string
+	Lines []coverageLine
+}
+
+var coverageTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Log coverage</title>
+<style>
+body { background: #1e1e1e; color: #ccc; font-family: Menlo, monospace; margin: 0; }
+#topbar { background: #111; padding: 8px 12px; position: sticky; top: 0; }
+#topbar span { margin-left: 16px; }
+.hit { color: #4ec94e; }
+.miss { color: #f05050; }
+pre { margin: 0; padding: 8px 12px; }
+.line { display: block; }
+.number { color: #666; display: inline-block; width: 5em; user-select: none; }
+.file { display: none; }
+</style>
+</head>
+<body>
+<div id="topbar">
+<select id="files">
+{{range .Files}}<option value="file{{.ID}}">{{.Title}}</option>
+{{end}}</select>
+<span>{{.Total}}</span>
+<span class="hit">logged</span>
+<span class="miss">never logged</span>
+</div>
+{{range .Files}}<pre class="file" id="file{{.ID}}">{{range .Lines}}<span class="line {{.Class}}"{{if .Title}} title="{{.Title}}"{{end}}><span class="number">{{.Number}}</span>{{.Text}}</span>{{end}}</pre>
+{{end}}<script>
+const files = document.getElementById("files");
+function show() {
+	for (const f of document.querySelectorAll(".file")) {
+		f.style.display = f.id === files.value ? "block" : "none";
+	}
+}
+files.addEventListener("change", show);
+show();
+</script>
+</body>
+</html>
+`))
+
+// Writes the report as a single HTML page showing each file's source
+// with its log statements marked as logged or never logged, in the
+// style of go tool cover.
+func (r CoverageReport) WriteHTML(w io.Writer) error {
+	files := []coverageFile{}
+	for i, f := range r.Files {
+		source, err := readSource(f.Path)
+		if err != nil {
+			continue
+		}
+
+		statements := map[int]StatementCoverage{}
+		for _, s := range f.Statements {
+			for line := s.Line; line <= max(s.Line, s.EndLine); line++ {
+				statements[line] = s
+			}
+		}
+
+		cf := coverageFile{ID: i, Path: f.Path, Title: fmt.Sprintf("%s (%.1f%%)", f.Path, f.Percent())}
+		for n, text := range strings.Split(strings.TrimSuffix(source, "\n"), "\n") {
+			line := coverageLine{Number: n + 1, Text: text}
+			if s, ok := statements[n+1]; ok {
+				line.Class, line.Title = "miss", "never logged"
+				if s.Hits > 0 {
+					line.Class, line.Title = "hit", fmt.Sprintf("logged %d times", s.Hits)
+					if s.Hits == 1 {
+						line.Title = "logged once"
+					}
+				}
+			}
+			cf.Lines = append(cf.Lines, line)
+		}
+		files = append(files, cf)
+	}
+
+	err := coverageTemplate.Execute(w, struct {
+		Files []coverageFile
+		Total string
+	}{files, fmt.Sprintf("%d of %d log statements seen (%.1f%%)", r.Hit, r.Statements, r.Percent())})
+	if err != nil {
+		return fmt.Errorf("error writing coverage report: %v", err)
+	}
+	return nil
+}
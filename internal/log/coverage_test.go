@@ -0,0 +1,66 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	root := t.TempDir()
+	src := "package svc\n\nfunc handle() {\n\tlog.Info(\"handling\")\n\tlog.Error(\n\t\t\"failed\")\n\tlog.Debug(\"done\")\n}\n"
+	path := filepath.Join(root, "svc", "handle.go")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	addMappedRoot(root)
+	defer func() {
+		mappedRootsMu.Lock()
+		delete(mappedRoots, root)
+		mappedRootsMu.Unlock()
+	}()
+
+	at := func(lines ...int) []SourceMapping {
+		sources := []SourceMapping{}
+		for _, line := range lines {
+			sources = append(sources, SourceMapping{Path: path, Line: line})
+		}
+		return sources
+	}
+	logs := []Log{
+		{Message: "handling", Sources: at(4)},
+		{Message: "handling", Sources: at(4)},
+		// The message of a call spanning lines is found after the call
+		{Message: "failed", Sources: at(6)},
+		// Only the selected source counts
+		{Message: "done", Sources: at(7, 4), SelectedSourceIdx: 1},
+	}
+
+	report := Coverage(logs)
+	if report.Statements != 3 || report.Hit != 2 || len(report.Files) != 1 || len(report.Packages) != 1 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+	hits := []int{}
+	for _, s := range report.Files[0].Statements {
+		hits = append(hits, s.Hits)
+	}
+	if len(hits) != 3 || hits[0] != 3 || hits[1] != 1 || hits[2] != 0 {
+		t.Errorf("Unexpected hits %v", hits)
+	}
+
+	var text strings.Builder
+	report.WriteText(&text)
+	if !strings.Contains(text.String(), "handle.go:7\tdebug\t\"done\"") {
+		t.Errorf("Expected the debug log to be listed as never logged, got\n%s", text.String())
+	}
+
+	var html strings.Builder
+	if err := report.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), `class="line hit" title="logged 3 times"`) {
+		t.Errorf("Expected the info log to be marked as logged in the HTML report")
+	}
+}
@@ -0,0 +1,135 @@
+package log
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterParser(csvParser{})
+}
+
+// Parses Datadog style CSV exports with the columns
+// Date, Host, Service, Message.
+type csvParser struct{}
+
+func (csvParser) Name() string { return "csv" }
+
+func (csvParser) Sniff(name string, head []string) float64 {
+	if strings.EqualFold(filepath.Ext(name), ".csv") {
+		return 0.9
+	}
+	if len(head) == 0 {
+		return 0
+	}
+
+	// Exports usually start with a header row
+	if strings.EqualFold(head[0], "Date,Host,Service,Message") {
+		return 0.9
+	}
+
+	record, err := csv.NewReader(strings.NewReader(head[len(head)/2])).Read()
+	if err != nil || len(record) < 4 {
+		return 0
+	}
+	if _, err := time.Parse("2006-01-02T15:04:05.000Z", record[0]); err == nil {
+		return 0.8
+	}
+	return 0.1
+}
+
+// Column names, lower cased, that hold each field in exports with a header row.
+var csvColumns = map[string]string{
+	"date": "time", "timestamp": "time", "time": "time", "@timestamp": "time",
+	"host": "host", "hostname": "host",
+	"service": "service",
+	"message": "message", "content": "message", "msg": "message",
+	"status": "level", "level": "level", "severity": "level",
+}
+
+func (csvParser) Parse(file io.Reader) ([]Log, error) {
+	// Parse CSV file, keeping each row as it was written since
+	// quoted messages can span lines
+	logs := []Log{}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV file: %v", err)
+	}
+	csvReader := csv.NewReader(bytes.NewReader(data))
+	csvReader.FieldsPerRecord = -1
+	records, raws := [][]string{}, []string{}
+	for {
+		start := csvReader.InputOffset()
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV file: %v", err)
+		}
+		records = append(records, record)
+		raws = append(raws, strings.TrimRight(string(data[start:csvReader.InputOffset()]), "\r\n"))
+	}
+
+	// Without a header the columns are Date, Host, Service, Message
+	columns := map[string]int{"time": 0, "host": 1, "service": 2, "message": 3}
+	if len(records) > 0 {
+		if _, ok := parseTime(records[0][0]); !ok {
+			header := map[string]int{}
+			for i, name := range records[0] {
+				if field, ok := csvColumns[strings.ToLower(strings.TrimSpace(name))]; ok {
+					header[field] = i
+				}
+			}
+			if _, ok := header["message"]; ok {
+				columns = header
+			}
+		}
+	}
+	column := func(record []string, field string) string {
+		if i, ok := columns[field]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	for i, record := range records {
+		if len(record) <= columns["message"] {
+			continue // Skip malformed lines
+		}
+
+		// Time is provided as 2025-06-19T03:40:54.794Z
+		time, ok := parseTime(column(record, "time"))
+		if !ok {
+			continue
+		}
+
+		l := Log{
+			Time:       time,
+			Service:    column(record, "service"),
+			Host:       column(record, "host"),
+			Level:      column(record, "level"),
+			Message:    column(record, "message"),
+			Raw:        raws[i],
+			Attributes: map[string]string{},
+			Sources:    []SourceMapping{}, // Sources are added later
+		}
+		// Older exports put the status where the host usually is
+		if _, ok := columns["level"]; !ok && isLevel(l.Host) {
+			l.Level, l.Host = l.Host, ""
+		}
+		// Exports of structured logs keep the whole JSON line as the message
+		if inner, ok := parseEmbedded(l.Message); ok {
+			mergeEmbedded(&l, inner)
+		}
+
+		logs = append(logs, l)
+	}
+
+	return logs, nil
+}
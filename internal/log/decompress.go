@@ -0,0 +1,77 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	// bzip2 streams start with a block, or end right away when empty
+	bzip2Block = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+	bzip2End   = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}
+)
+
+// Wraps the provided reader with a decompressor when the content starts
+// with a known compression magic number. Archives can be nested
+// (ie. logs.csv.gz.bz2) so this keeps unwrapping until the content is plain.
+// Returns the decompressed stream along with the name of the inner file,
+// which is used to detect the log format of the archive contents.
+func decompress(r io.Reader, name string) (*bufio.Reader, string, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+	for {
+		magic, err := br.Peek(10)
+		if err != nil && err != io.EOF {
+			return nil, name, fmt.Errorf("error reading log file: %v", err)
+		}
+
+		switch {
+		case bytes.HasPrefix(magic, gzipMagic):
+			gz, err := gzip.NewReader(br)
+			if err != nil {
+				return nil, name, fmt.Errorf("error reading gzip archive: %v", err)
+			}
+			// gzip records the original file name when it is available
+			// which is more reliable than our upload temp file name
+			if gz.Name != "" {
+				name = gz.Name
+			} else {
+				name = trimExt(name, ".gz", ".gzip")
+			}
+			br = bufio.NewReaderSize(gz, 64*1024)
+		case isBzip2(magic):
+			name = trimExt(name, ".bz2", ".bzip2")
+			br = bufio.NewReaderSize(bzip2.NewReader(br), 64*1024)
+		default:
+			return br, name, nil
+		}
+	}
+}
+
+// Reports whether the content starts with a bzip2 header: BZh, the block
+// size from 1 to 9 and the magic of the first block. Text logs can start
+// with BZh too.
+func isBzip2(magic []byte) bool {
+	if len(magic) < 10 || !bytes.HasPrefix(magic, bzip2Magic) || magic[3] < '1' || magic[3] > '9' {
+		return false
+	}
+	return bytes.Equal(magic[4:], bzip2Block) || bytes.Equal(magic[4:], bzip2End)
+}
+
+// Removes the first matching compression extension from a file name.
+func trimExt(name string, exts ...string) string {
+	ext := filepath.Ext(name)
+	for _, e := range exts {
+		if strings.EqualFold(ext, e) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
@@ -0,0 +1,81 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func TestDecompressGzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Name = "logs.csv"
+	gz.Write([]byte("2025-06-19T03:39:21.231Z,host,pi,Failed to get user by filters\n"))
+	gz.Close()
+
+	br, name, err := decompress(&buf, "/tmp/vlsa_upload_123.gz")
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+	if name != "logs.csv" {
+		t.Errorf("Expected inner name logs.csv, got %s", name)
+	}
+	content, _ := io.ReadAll(br)
+	if !bytes.HasPrefix(content, []byte("2025-06-19")) {
+		t.Errorf("Expected decompressed content, got %q", content)
+	}
+}
+
+func TestDecompressPlain(t *testing.T) {
+	br, name, err := decompress(bytes.NewBufferString("plain log line\n"), "app.log")
+	if err != nil {
+		t.Fatalf("Failed to read plain file: %v", err)
+	}
+	if name != "app.log" {
+		t.Errorf("Expected name to be unchanged, got %s", name)
+	}
+	content, _ := io.ReadAll(br)
+	if string(content) != "plain log line\n" {
+		t.Errorf("Expected content to be unchanged, got %q", content)
+	}
+}
+
+// "BZh9 backup finished\n" compressed with bzip2 -9.
+var bzip2Log = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x09, 0xe3, 0x76, 0x71, 0x00, 0x00, 0x02,
+	0x5f, 0x80, 0x00, 0x10, 0x40, 0x00, 0x00, 0x20, 0x10, 0x00, 0x00, 0x10, 0x3f, 0x69, 0x4a, 0x00, 0x20,
+	0x00, 0x31, 0x4d, 0x32, 0x31, 0x31, 0x31, 0x08, 0x46, 0x11, 0x88, 0x62, 0x6c, 0xa7, 0x86, 0xad, 0xa6,
+	0x85, 0x27, 0xae, 0xd7, 0xa6, 0xf1, 0x7f, 0x17, 0x72, 0x45, 0x38, 0x50, 0x90, 0x09, 0xe3, 0x76, 0x71,
+}
+
+func TestDecompressBzip2(t *testing.T) {
+	br, name, err := decompress(bytes.NewReader(bzip2Log), "logs.txt.bz2")
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+	content, _ := io.ReadAll(br)
+	if name != "logs.txt" || string(content) != "BZh9 backup finished\n" {
+		t.Errorf("Expected the decompressed logs.txt, got %q from %s", content, name)
+	}
+
+	// Text that happens to start like a bzip2 header stays as it is
+	for _, text := range []string{"BZh9 backup finished\n", "BZh", "BZhx\n"} {
+		br, _, err := decompress(bytes.NewBufferString(text), "app.log")
+		if err != nil {
+			t.Fatalf("Failed to read plain file %q: %v", text, err)
+		}
+		if content, _ := io.ReadAll(br); string(content) != text {
+			t.Errorf("Expected %q to be unchanged, got %q", text, content)
+		}
+	}
+}
+
+func TestTrimExt(t *testing.T) {
+	if got := trimExt("logs.csv.bz2", ".bz2"); got != "logs.csv" {
+		t.Errorf("Expected logs.csv, got %s", got)
+	}
+	if got := trimExt("logs.csv", ".gz"); got != "logs.csv" {
+		t.Errorf("Expected logs.csv, got %s", got)
+	}
+}
@@ -0,0 +1,260 @@
+package log
+
+import (
+	"bufio"
+	"go/build"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// A Go module the source root requires, whose source is searched
+// after the root's own.
+type Dependency struct {
+	Path    string // Module path, like google.golang.org/grpc
+	Version string // Version required, empty for local replacements
+	Dir     string // Directory the module's source is in
+}
+
+// Returns the module path and version, the way go.mod names the module.
+func (d Dependency) Label() string {
+	if d.Version == "" {
+		return d.Path
+	}
+	return d.Path + "@" + d.Version
+}
+
+var dependencyCache = map[string][]Dependency{}
+
+// Resolves the modules required by the go.mod at the root to their
+// source, in vendor/ when the root vendors its dependencies or else
+// in the module cache. Modules that were never downloaded are left out.
+func Dependencies(root string) []Dependency {
+	if deps, found := dependencyCache[root]; found {
+		return deps
+	}
+	deps := []Dependency{}
+	dependencyCache[root] = deps
+
+	dir := root
+	if dir == "" {
+		dir = "."
+	}
+	if repo, ref, ok := gitSpec(root); ok {
+		dir = revisionPath(resolveRevision(repo, ref), repo)
+	}
+	goMod, err := readSource(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return deps
+	}
+	vendored := vendoredModules(dir)
+	for _, d := range parseGoMod(goMod) {
+		switch {
+		case vendored[d.Path]:
+			d.Dir = filepath.Join(dir, "vendor", filepath.FromSlash(d.Path))
+		case d.Version == "":
+			// Replaced by a directory relative to the go.mod
+			if !filepath.IsAbs(d.Dir) {
+				d.Dir = filepath.Join(dir, d.Dir)
+			}
+		default:
+			d.Dir = filepath.Join(moduleCache(), escapeModulePath(d.Path)+"@"+escapeModulePath(d.Version))
+		}
+		// Paths at a revision are kept the way git lists them
+		if rev, path := SplitRevision(d.Dir); rev != "" {
+			d.Dir = revisionPath(rev, filepath.Clean(path))
+		}
+		if _, ok := treeOf(d.Dir).(dirTree); ok {
+			if info, err := os.Stat(d.Dir); err == nil && info.IsDir() {
+				deps = append(deps, d)
+			}
+		} else if len(dependencyFiles(d.Dir)) > 0 {
+			deps = append(deps, d)
+		}
+	}
+	dependencyCache[root] = deps
+	return deps
+}
+
+// Reads the required modules from go.mod, with replacements applied.
+// Modules replaced by a directory keep it in Dir and have no version.
+func parseGoMod(src string) []Dependency {
+	requires := []Dependency{}
+	replaces := map[string]Dependency{}
+
+	block := ""
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		verb := block
+		if block == "" {
+			verb, fields = fields[0], fields[1:]
+			if len(fields) == 1 && fields[0] == "(" {
+				block = verb
+				continue
+			}
+		} else if fields[0] == ")" {
+			block = ""
+			continue
+		}
+
+		switch verb {
+		case "require":
+			if len(fields) >= 2 {
+				requires = append(requires, Dependency{Path: fields[0], Version: fields[1]})
+			}
+		case "replace":
+			// old [version] => new [version]
+			old, repl, found := strings.Cut(strings.Join(fields, " "), "=>")
+			oldFields, replFields := strings.Fields(old), strings.Fields(repl)
+			if !found || len(oldFields) == 0 || len(replFields) == 0 {
+				continue
+			}
+			d := Dependency{Path: replFields[0]}
+			if len(replFields) >= 2 {
+				d.Version = replFields[1]
+			} else {
+				d.Dir = replFields[0]
+			}
+			replaces[oldFields[0]] = d
+		}
+	}
+
+	for i, r := range requires {
+		if d, ok := replaces[r.Path]; ok {
+			if d.Version == "" {
+				// Local replacements are still labeled with the module they replace
+				d.Path = r.Path
+			}
+			requires[i] = d
+		}
+	}
+	return requires
+}
+
+// Returns the modules listed in vendor/modules.txt.
+func vendoredModules(root string) map[string]bool {
+	modules := map[string]bool{}
+	src, err := readSource(filepath.Join(root, "vendor", "modules.txt"))
+	if err != nil {
+		return modules
+	}
+	for _, line := range strings.Split(src, "\n") {
+		// # path version [=> replacement]
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "#" {
+			modules[fields[1]] = true
+		}
+	}
+	return modules
+}
+
+var modCache string
+
+// Returns the module cache directory, as go env GOMODCACHE would.
+func moduleCache() string {
+	if modCache != "" {
+		return modCache
+	}
+	modCache = os.Getenv("GOMODCACHE")
+	if modCache == "" {
+		if out, err := exec.Command("go", "env", "GOMODCACHE").Output(); err == nil {
+			modCache = strings.TrimSpace(string(out))
+		}
+	}
+	if modCache == "" {
+		modCache = filepath.Join(filepath.SplitList(build.Default.GOPATH)[0], "pkg", "mod")
+	}
+	return modCache
+}
+
+// Escapes a module path or version for the module cache, which
+// writes upper case letters as ! followed by the lower case letter.
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Searches the modules the root depends on for the message. Sources
+// are labeled with the module they were found in.
+func dependencySources(msg string, root string) []SourceMapping {
+	sm := parseOutDynamics(msg, true)
+	if sm == "" {
+		return nil
+	}
+	depsRoot := "deps:" + root
+	key := cacheKey(depsRoot, sm)
+	if sources, found := searchCache[key]; found {
+		return sources
+	}
+
+	sources := []SourceMapping{}
+	if out, found := rgSearch([]string{"-F", sm}, depsRoot); found {
+		for _, s := range parseRGOutput(out) {
+			labelDependency(&s, root)
+			sources = append(sources, s)
+		}
+	}
+	searchCache[key] = sources
+	return sources
+}
+
+// Labels the source with the module it is in, when it is in one of
+// the root's dependencies. Reports whether it is.
+func labelDependency(s *SourceMapping, root string) bool {
+	path := filepath.Clean(s.Path)
+	for _, d := range Dependencies(root) {
+		if strings.HasPrefix(path, filepath.Clean(d.Dir)+string(filepath.Separator)) {
+			s.Module, s.moduleDir = d.Label(), filepath.Clean(d.Dir)
+			return true
+		}
+	}
+	return false
+}
+
+// Orders the sources in dependencies after the root's own. Searching
+// the root already finds the modules it vendors, those are labeled
+// and moved down too. The root's unmapped placeholder is kept only
+// when nothing was found anywhere.
+func appendDependencySources(sources []SourceMapping, deps []SourceMapping, root string) []SourceMapping {
+	own, vendored := []SourceMapping{}, []SourceMapping{}
+	for _, s := range sources {
+		switch {
+		case s.Path == "":
+		case labelDependency(&s, root):
+			vendored = append(vendored, s)
+		default:
+			own = append(own, s)
+		}
+	}
+	merged := mergeSources(own, mergeSources(vendored, deps))
+	if len(merged) == 0 {
+		return sources
+	}
+	return merged
+}
+
+// Returns where the source is, naming files in dependencies by their
+// module, like google.golang.org/grpc@v1.60.0/server.go.
+func (s SourceMapping) DisplayPath() string {
+	if s.Module == "" {
+		return s.Path
+	}
+	rel, err := filepath.Rel(s.moduleDir, filepath.Clean(s.Path))
+	if err != nil {
+		return s.Path
+	}
+	return s.Module + "/" + filepath.ToSlash(rel)
+}
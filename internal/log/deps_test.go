@@ -0,0 +1,100 @@
+package log
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	deps := parseGoMod(`module example.com/shop
+
+go 1.24
+
+require github.com/lib/pq v1.10.9
+
+require (
+	google.golang.org/grpc v1.60.0 // indirect
+	example.com/shop/proto v0.1.0
+	github.com/old/driver v1.0.0
+)
+
+replace example.com/shop/proto => ../proto
+
+replace (
+	github.com/old/driver v1.0.0 => github.com/new/driver v1.2.0
+)
+`)
+	expected := []Dependency{
+		{Path: "github.com/lib/pq", Version: "v1.10.9"},
+		{Path: "google.golang.org/grpc", Version: "v1.60.0"},
+		{Path: "example.com/shop/proto", Dir: "../proto"},
+		{Path: "github.com/new/driver", Version: "v1.2.0"},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, deps)
+	}
+}
+
+func TestDependencySources(t *testing.T) {
+	if _, err := exec.LookPath("rg"); err != nil {
+		t.Skip("ripgrep is not installed")
+	}
+	root := t.TempDir()
+	cache := t.TempDir()
+	modCache = cache
+	defer func() { modCache = "" }()
+
+	write := func(path, src string) {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(src), 0644)
+	}
+	write(filepath.Join(root, "go.mod"), "module example.com/shop\n\nrequire (\n\tgithub.com/Shopify/sarama v1.38.1\n\tgithub.com/lib/pq v1.10.9\n\tgithub.com/missing/mod v1.0.0\n)\n")
+	write(filepath.Join(root, "main.go"), "package main\n\nfunc main() {\n\tlog.Print(\"connection reset by peer\")\n}\n")
+	// Upper case letters are escaped in the module cache
+	write(filepath.Join(cache, "github.com/!shopify/sarama@v1.38.1/broker.go"), "package sarama\n\nfunc (b *Broker) Open() {\n\tLogger.Printf(\"connection reset by peer\")\n}\n")
+	// Vendored modules win over the module cache
+	write(filepath.Join(root, "vendor/modules.txt"), "# github.com/lib/pq v1.10.9\n## explicit\ngithub.com/lib/pq\n")
+	write(filepath.Join(root, "vendor/github.com/lib/pq/conn.go"), "package pq\n\nfunc errorf() {\n\tlog.Printf(\"pq: connection reset by peer\")\n}\n")
+
+	deps := Dependencies(root)
+	if len(deps) != 2 || deps[0].Label() != "github.com/Shopify/sarama@v1.38.1" || deps[1].Dir != filepath.Join(root, "vendor/github.com/lib/pq") {
+		t.Fatalf("Unexpected dependencies %+v", deps)
+	}
+
+	// Dependencies come after the root's own source
+	own := rg("connection reset by peer", root)
+	sources := appendDependencySources(own, dependencySources("connection reset by peer", root), root)
+	if len(sources) != 3 || sources[0].Path != filepath.Join(root, "main.go") || sources[0].Module != "" {
+		t.Fatalf("Unexpected sources %+v", sources)
+	}
+	labels := map[string]bool{}
+	for _, s := range sources[1:] {
+		labels[s.DisplayPath()] = true
+	}
+	if !labels["github.com/Shopify/sarama@v1.38.1/broker.go"] || !labels["github.com/lib/pq@v1.10.9/conn.go"] {
+		t.Errorf("Expected dependency sources labeled with their module, got %v", labels)
+	}
+}
+
+func TestRevisionDependencies(t *testing.T) {
+	root, commit := testGitRepo(t)
+	os.MkdirAll(filepath.Join(root, "vendor/github.com/lib/pq"), 0755)
+	commit("go.mod", "module example.com/shop\n\nrequire github.com/lib/pq v1.10.9\n")
+	commit("vendor/modules.txt", "# github.com/lib/pq v1.10.9\n## explicit\ngithub.com/lib/pq\n")
+	rev := commit("vendor/github.com/lib/pq/conn.go", "package pq\n\nfunc errorf() {\n\tlog.Printf(\"pq: connection reset by peer\")\n}\n")
+	// Vendoring was dropped since
+	os.RemoveAll(filepath.Join(root, "vendor"))
+
+	deps := Dependencies("git:" + root)
+	dir := revisionPath(rev, filepath.Join(root, "vendor/github.com/lib/pq"))
+	if len(deps) != 1 || deps[0].Dir != dir {
+		t.Fatalf("Unexpected dependencies %+v", deps)
+	}
+	sources := dependencySources("connection reset by peer", "git:"+root)
+	if len(sources) != 1 || sources[0].DisplayPath() != "github.com/lib/pq@v1.10.9/conn.go" || sources[0].Line != 4 {
+		t.Errorf("Unexpected dependency sources %+v", sources)
+	}
+}
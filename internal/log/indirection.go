@@ -0,0 +1,172 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Declarations that give a message a name instead of logging it:
+// constants, variables and enum values in the common languages.
+var declarationPatterns = []*regexp.Regexp{
+	// const msg = "...", pub const MSG: &str = "...", let msg = '...'
+	regexp.MustCompile(`^\s*(?:export\s+)?(?:pub(?:\([\w:]+\))?\s+)?(?:const|static|var|let|val)\s+(?:mut\s+)?([A-Za-z_]\w*)\b[^=(]*=\s*[fr]?["'` + "`" + `]`),
+	// private static final String MSG = "..."
+	regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|final|readonly)\s+)+[\w<>\[\]]+\s+([A-Za-z_]\w*)\s*=\s*["']`),
+	// Members of a const block, module level Python constants and TypeScript enums
+	regexp.MustCompile(`^\s*([A-Za-z_]\w*)(?:\s+\w+)?\s*(?::=|=)\s*[fr]?["'` + "`" + `]`),
+	// Java enums, USER_NOT_FOUND("...")
+	regexp.MustCompile(`^\s*([A-Z][A-Z0-9_]*)\s*\(\s*"`),
+}
+
+// Rust error enums attach the message to the variant on the next line.
+var (
+	rustErrorAttribute = regexp.MustCompile(`^\s*#\[error\(`)
+	rustVariant        = regexp.MustCompile(`^([A-Za-z_]\w*)`)
+)
+
+// Keys of translation catalogs, "errors.user_not_found": "..." in JSON,
+// errors.user_not_found=... in properties or user_not_found: ... in YAML.
+var catalogEntry = regexp.MustCompile(`^\s*["']?([\w.-]+)["']?\s*[:=]`)
+
+var catalogExts = map[string]bool{".json": true, ".yaml": true, ".yml": true, ".properties": true, ".toml": true}
+
+// References past this many are too generic to be useful, like a local
+// variable named msg, so only the ones in the declaring file are kept.
+const maxReferences = 10
+
+// Returns the name a source line gives to the message it contains,
+// or "" when the line isn't a declaration.
+func declaredName(source SourceMapping) (name string, catalog bool) {
+	lines := strings.Split(source.SourceCode, "\n")
+	if source.Line < 1 || source.Line > len(lines) {
+		return "", false
+	}
+	line := lines[source.Line-1]
+	if isCommentLine(line) {
+		return "", false
+	}
+
+	if catalogExts[strings.ToLower(filepath.Ext(source.Path))] {
+		if m := catalogEntry.FindStringSubmatch(line); m != nil {
+			return m[1], true
+		}
+		return "", false
+	}
+
+	if rustErrorAttribute.MatchString(line) {
+		for _, next := range lines[source.Line:] {
+			next = strings.TrimSpace(next)
+			if next == "" || strings.HasPrefix(next, "#[") || strings.HasPrefix(next, "//") {
+				continue
+			}
+			if m := rustVariant.FindStringSubmatch(next); m != nil {
+				return m[1], false
+			}
+			break
+		}
+		return "", false
+	}
+
+	for _, p := range declarationPatterns {
+		if m := p.FindStringSubmatch(line); m != nil {
+			return m[1], false
+		}
+	}
+	return "", false
+}
+
+// Follows sources that only declare the message, like a constant or a
+// translation key, to the places that name is used. The call sites take
+// the place of the declaration, which is kept after them as context.
+func followIndirection(sources []SourceMapping, root string) []SourceMapping {
+	followed := []SourceMapping{}
+	for _, s := range sources {
+		name, catalog := declaredName(s)
+		if name == "" {
+			followed = append(followed, s)
+			continue
+		}
+
+		refs := references(name, catalog, s, root)
+		for _, r := range refs {
+			r.DisplayMessage = fmt.Sprintf("Uses %s, declared at %s:%d", name, s.Path, s.Line)
+			followed = append(followed, r)
+		}
+		if len(refs) > 0 {
+			s.DisplayMessage = "Declaration of " + name
+		}
+		followed = append(followed, s)
+	}
+	// Placeholders for unmapped logs have no path and would be merged away
+	if len(followed) == len(sources) {
+		return sources
+	}
+	return mergeSources(nil, followed)
+}
+
+// Searches for the places a declared name is used, leaving out
+// the declaration itself and any other declarations of the name.
+func references(name string, catalog bool, decl SourceMapping, root string) []SourceMapping {
+	// Which references are kept depends on the declaration
+	key := cacheKey(root, fmt.Sprintf("ref:%s:%v:%s:%d", name, catalog, decl.Path, decl.Line))
+	if refs, found := searchCache[key]; found {
+		return refs
+	}
+
+	// Catalog keys are looked up by string, identifiers by word
+	args := []string{"-w", "-F", name}
+	if catalog {
+		args = []string{"-F", "-e", `"` + name + `"`, "-e", "'" + name + "'"}
+	}
+
+	refs := []SourceMapping{}
+	if out, found := rgSearch(args, root); found {
+		for _, r := range parseRGOutput(out) {
+			if r.Path == decl.Path && r.Line == decl.Line || isTestFile(r.Path) || catalogExts[strings.ToLower(filepath.Ext(r.Path))] {
+				continue
+			}
+			if n, _ := declaredName(r); n == name {
+				continue
+			}
+			lines := strings.Split(r.SourceCode, "\n")
+			if r.Line <= len(lines) && (isCommentLine(lines[r.Line-1]) || isVariant(lines[r.Line-1], name)) {
+				continue
+			}
+			refs = append(refs, r)
+		}
+	}
+
+	if len(refs) > maxReferences {
+		local := []SourceMapping{}
+		for _, r := range refs {
+			if filepath.Clean(r.Path) == filepath.Clean(decl.Path) {
+				local = append(local, r)
+			}
+		}
+		refs = local
+	}
+
+	searchCache[key] = refs
+	return refs
+}
+
+// Reports whether the whole line is a comment. Rust attributes
+// start with # too but aren't comments.
+func isCommentLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range []string{"//", "/*", "*", "--"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "#[")
+}
+
+// Reports whether the line declares the name as an enum variant, like
+// RulesMissing, or RulesMissing(String), below a Rust #[error(...)].
+func isVariant(line string, name string) bool {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), name)
+	return ok && (rest == "" || strings.ContainsAny(rest[:1], ",({"))
+}
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeclaredName(t *testing.T) {
+	tests := []struct {
+		path     string
+		code     string
+		line     int
+		expected string
+		catalog  bool
+	}{
+		{"user.go", "const msgUserNotFound = \"Failed to get user by id\"\n", 1, "msgUserNotFound", false},
+		{"user.go", "const (\n\tmsgUserNotFound = \"Failed to get user by id\"\n)\n", 2, "msgUserNotFound", false},
+		{"lib.rs", "pub const NOT_FOUND: &str = \"Failed to get user\";\n", 1, "NOT_FOUND", false},
+		{"Errors.java", "    private static final String NOT_FOUND = \"Failed to get user\";\n", 1, "NOT_FOUND", false},
+		{"Errors.java", "    USER_NOT_FOUND(\"Failed to get user\"),\n", 1, "USER_NOT_FOUND", false},
+		{"errors.rs", "    #[error(\"Failed to load rules\")]\n    #[allow(dead_code)]\n    RulesMissing,\n", 1, "RulesMissing", false},
+		{"en.json", "  \"errors.quota\": \"Quota exceeded\",\n", 1, "errors.quota", true},
+		{"user.go", "\tlogger.Error(\"Failed to get user by id\")\n", 1, "", false},
+		{"user.go", "// msg = \"Failed to get user by id\"\n", 1, "", false},
+	}
+
+	for _, tt := range tests {
+		name, catalog := declaredName(SourceMapping{Path: tt.path, Line: tt.line, SourceCode: tt.code})
+		if name != tt.expected || catalog != tt.catalog {
+			t.Errorf("declaredName(%q) = %q %v, want %q %v", tt.code, name, catalog, tt.expected, tt.catalog)
+		}
+	}
+}
+
+func TestFollowIndirection(t *testing.T) {
+	if _, err := exec.LookPath("rg"); err != nil {
+		t.Skip("ripgrep is not installed")
+	}
+
+	root := t.TempDir()
+	code := "package svc\n\nconst msgUserNotFound = \"Failed to get user by id\"\n\nfunc get() {\n\t// msgUserNotFound is logged here\n\tlogger.Error(msgUserNotFound)\n}\n"
+	path := filepath.Join(root, "user.go")
+	if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sources := followIndirection([]SourceMapping{{Path: path, Line: 3, SourceCode: code}}, root)
+	if len(sources) != 2 {
+		t.Fatalf("Expected the call site and the declaration, got %+v", sources)
+	}
+	if sources[0].Line != 7 || sources[1].Line != 3 || sources[1].DisplayMessage != "Declaration of msgUserNotFound" {
+		t.Errorf("Unexpected sources %+v", sources)
+	}
+}
+
+func TestFollowIndirectionKeepsPlaceholder(t *testing.T) {
+	placeholder := []SourceMapping{{DisplayMessage: "No source mapping found for this log message..."}}
+	if sources := followIndirection(placeholder, t.TempDir()); len(sources) != 1 || sources[0].DisplayMessage != placeholder[0].DisplayMessage {
+		t.Errorf("Expected the placeholder to be kept, got %+v", sources)
+	}
+}
+
+func TestReferencesPerDeclaration(t *testing.T) {
+	if _, err := exec.LookPath("rg"); err != nil {
+		t.Skip("ripgrep is not installed")
+	}
+
+	// Both files declare and use msg more often than maxReferences allows
+	// in total, so each declaration keeps only the references in its file
+	root := t.TempDir()
+	code := "package svc\n\nconst msg = \"Failed to get user by id\"\n\nfunc get() {\n"
+	for i := 0; i < maxReferences/2+1; i++ {
+		code += "\tlogger.Error(msg)\n"
+	}
+	code += "}\n"
+	for _, name := range []string{"a.go", "b.go"} {
+		os.WriteFile(filepath.Join(root, name), []byte(code), 0644)
+	}
+
+	for _, name := range []string{"a.go", "b.go"} {
+		path := filepath.Join(root, name)
+		refs := references("msg", false, SourceMapping{Path: path, Line: 3, SourceCode: code}, root)
+		if len(refs) == 0 {
+			t.Errorf("Expected references to the msg declared in %s", name)
+		}
+		for _, r := range refs {
+			if r.Path != path {
+				t.Errorf("Expected only references in %s, got %s:%d", name, r.Path, r.Line)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterParser(journaldParser{})
+}
+
+// Parses the output of journalctl -o json, one journal entry per line.
+type journaldParser struct{}
+
+func (journaldParser) Name() string { return "journald" }
+
+func (journaldParser) Sniff(name string, head []string) float64 {
+	return sniffAll(head, 0.95, func(line string) bool {
+		var obj map[string]any
+		if json.Unmarshal([]byte(line), &obj) != nil {
+			return false
+		}
+		_, hasTimestamp := obj["__REALTIME_TIMESTAMP"]
+		_, hasMessage := obj["MESSAGE"]
+		return hasTimestamp && hasMessage
+	})
+}
+
+func (journaldParser) Parse(file io.Reader) ([]Log, error) {
+	logs := []Log{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var entry map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue // Skip lines that are not JSON objects
+		}
+
+		l := Log{Raw: scanner.Text(), Attributes: map[string]string{}, Sources: []SourceMapping{}}
+		for k, v := range entry {
+			value := journalValue(v)
+			switch k {
+			case "__REALTIME_TIMESTAMP":
+				// Microseconds since the epoch
+				if us, err := strconv.ParseInt(value, 10, 64); err == nil {
+					l.Time = time.UnixMicro(us).UTC()
+				}
+			case "MESSAGE":
+				l.Message = value
+			case "PRIORITY":
+				if p, err := strconv.Atoi(value); err == nil && p >= 0 && p < len(syslogSeverities) {
+					l.Level = syslogSeverities[p]
+				}
+			case "_HOSTNAME":
+				l.Host = value
+			default:
+				// Cursors and other journal internals aren't useful to show
+				if !strings.HasPrefix(k, "__") {
+					l.Attributes[k] = value
+				}
+			}
+		}
+
+		// Prefer the identifier the program logged with over its unit
+		l.Service = l.Attributes["SYSLOG_IDENTIFIER"]
+		if l.Service == "" {
+			l.Service = strings.TrimSuffix(l.Attributes["_SYSTEMD_UNIT"], ".service")
+		}
+
+		// Programs using sd_journal_print record where the log was written
+		if file := l.Attributes["CODE_FILE"]; file != "" {
+			l.Caller = file
+			if line := l.Attributes["CODE_LINE"]; line != "" {
+				l.Caller += ":" + line
+			}
+		}
+		logs = append(logs, l)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading log file: %v", err)
+	}
+
+	return logs, nil
+}
+
+// Journal fields are strings, or arrays of bytes when the
+// value isn't valid UTF-8.
+func journalValue(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []any:
+		b := make([]byte, 0, len(t))
+		for _, c := range t {
+			if f, ok := c.(float64); ok {
+				b = append(b, byte(f))
+			}
+		}
+		return string(b)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(t)
+	}
+}
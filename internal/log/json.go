@@ -0,0 +1,176 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterParser(jsonParser{})
+}
+
+// Parses JSON lines (one JSON object per line) as written by most
+// structured loggers.
+type jsonParser struct{}
+
+func (jsonParser) Name() string { return "json" }
+
+func (jsonParser) Sniff(name string, head []string) float64 {
+	if len(head) == 0 {
+		return 0
+	}
+
+	objects := 0
+	for _, line := range head {
+		var obj map[string]any
+		if json.Unmarshal([]byte(line), &obj) == nil {
+			objects++
+		}
+	}
+	score := 0.7 * float64(objects) / float64(len(head))
+	if ext := strings.ToLower(filepath.Ext(name)); score > 0 && (ext == ".jsonl" || ext == ".ndjson") {
+		score += 0.1
+	}
+	return score
+}
+
+func (jsonParser) Parse(file io.Reader) ([]Log, error) {
+	logs := []Log{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		l, ok := parseJSONLine(line)
+		if !ok {
+			continue // Skip lines that are not JSON objects
+		}
+		logs = append(logs, l)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading log file: %v", err)
+	}
+
+	return logs, nil
+}
+
+func parseJSONLine(line string) (Log, bool) {
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		return Log{}, false
+	}
+	// Rust tracing nests the message and its fields under fields
+	if fields, ok := obj["fields"].(map[string]any); ok {
+		delete(obj, "fields")
+		obj = mergeFields(obj, fields)
+	}
+
+	// Known fields are taken out of the object, whatever is left over
+	// is kept as attributes
+	take := func(keys ...string) any {
+		for _, k := range keys {
+			if v, ok := obj[k]; ok {
+				delete(obj, k)
+				return v
+			}
+		}
+		return nil
+	}
+
+	l := Log{Message: line, Raw: line, Attributes: map[string]string{}, Sources: []SourceMapping{}}
+	if t, ok := jsonTime(take("time", "timestamp", "ts", "@timestamp")); ok {
+		l.Time = t
+	}
+	l.Service = jsonString(take("service", "service.name", "app"))
+	l.Host = jsonString(take("host", "hostname", "host.name"))
+	l.Level = jsonString(take("level", "lvl", "severity", "log.level", "levelname"))
+	l.TraceID = jsonString(take("trace_id", "traceId", "traceID", "trace.id", "dd.trace_id"))
+	l.SpanID = jsonString(take("span_id", "spanId", "spanID", "span.id", "dd.span_id"))
+	if msg := take("msg", "message"); msg != nil {
+		l.Message = jsonString(msg)
+	}
+
+	// Loggers either record the caller as file:line or as separate fields
+	l.Caller = jsonString(take("caller"))
+	if file := jsonString(take("filename", "file")); file != "" && l.Caller == "" {
+		l.Caller = file
+		if line := jsonString(take("line_number", "lineno", "line")); line != "" {
+			l.Caller += ":" + line
+		}
+	}
+
+	for k, v := range obj {
+		l.Attributes[k] = jsonString(v)
+	}
+	return l, true
+}
+
+// Renders a JSON value as text, objects and arrays stay JSON.
+func jsonString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
+
+// Adds the nested fields to a copy of the object without
+// overwriting the top level keys.
+func mergeFields(obj map[string]any, fields map[string]any) map[string]any {
+	merged := make(map[string]any, len(obj)+len(fields))
+	for k, v := range fields {
+		merged[k] = v
+	}
+	for k, v := range obj {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Parses a JSON timestamp which is either a formatted string
+// or a unix epoch in seconds, milliseconds or nanoseconds.
+func jsonTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case string:
+		if ts, ok := parseTime(t); ok {
+			return ts, true
+		}
+		if f, err := strconv.ParseFloat(t, 64); err == nil {
+			return epochTime(f), true
+		}
+	case float64:
+		return epochTime(t), true
+	}
+	return time.Time{}, false
+}
+
+// Guesses the unit of a unix epoch from its magnitude.
+func epochTime(f float64) time.Time {
+	switch {
+	case f > 1e17:
+		return time.Unix(0, int64(f)).UTC()
+	case f > 1e14:
+		return time.UnixMicro(int64(f)).UTC()
+	case f > 1e11:
+		return time.UnixMilli(int64(f)).UTC()
+	default:
+		return time.Unix(0, int64(f*float64(time.Second))).UTC()
+	}
+}
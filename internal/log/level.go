@@ -0,0 +1,61 @@
+package log
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Normalized severities, from least to most severe.
+const (
+	LevelTrace = "trace"
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// Names used for each severity by syslog, journald, OpenTelemetry,
+// log4j, Python logging and the common structured loggers.
+var levelAliases = map[string]string{
+	"trace": LevelTrace, "finest": LevelTrace, "finer": LevelTrace, "verbose": LevelTrace,
+	"debug": LevelDebug, "dbug": LevelDebug, "fine": LevelDebug, "config": LevelDebug,
+	"info": LevelInfo, "information": LevelInfo, "informational": LevelInfo, "notice": LevelInfo, "ok": LevelInfo,
+	"warn": LevelWarn, "warning": LevelWarn, "wrn": LevelWarn,
+	"error": LevelError, "err": LevelError, "eror": LevelError, "severe": LevelError,
+	"fatal": LevelFatal, "critical": LevelFatal, "crit": LevelFatal, "alert": LevelFatal,
+	"emergency": LevelFatal, "emerg": LevelFatal, "panic": LevelFatal, "dpanic": LevelFatal,
+}
+
+// Maps the many spellings of a log level onto trace, debug, info, warn,
+// error or fatal. Numeric levels are read as bunyan/pino levels (10-60).
+// Levels that aren't recognized are returned lower cased.
+func NormalizeLevel(level string) string {
+	l := strings.ToLower(strings.TrimSpace(level))
+	if normalized, ok := levelAliases[l]; ok {
+		return normalized
+	}
+	if n, err := strconv.Atoi(l); err == nil && n >= 10 {
+		switch {
+		case n < 20:
+			return LevelTrace
+		case n < 30:
+			return LevelDebug
+		case n < 40:
+			return LevelInfo
+		case n < 50:
+			return LevelWarn
+		case n < 60:
+			return LevelError
+		default:
+			return LevelFatal
+		}
+	}
+	return l
+}
+
+// Reports whether the value is a recognized log level name.
+func isLevel(s string) bool {
+	_, ok := levelAliases[strings.ToLower(strings.TrimSpace(s))]
+	return ok
+}
@@ -0,0 +1,208 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"vlsa/internal/analysis"
+)
+
+// A problem that makes a log statement hard to map back to the source.
+type LintRule struct {
+	ID          string
+	Description string
+}
+
+var (
+	ruleAmbiguous = LintRule{"ambiguous-message", "The message can't be told apart from other log statements, so its logs map to several places"}
+	ruleGeneric   = LintRule{"generic-message", "The message is too short or common to search the source for"}
+	ruleDynamic   = LintRule{"dynamic-message", "The message is mostly built at runtime, leaving little text to search for"}
+	lintRules     = []LintRule{ruleAmbiguous, ruleGeneric, ruleDynamic}
+)
+
+// Messages searched for with fewer characters than this are too generic.
+const minSearchLength = 10
+
+// Placeholders filled in when the message is logged: printf verbs,
+// {} and {name} templates and ${} or #{} interpolation.
+var placeholder = regexp.MustCompile(`%[-+# 0-9.*]*[a-zA-Z]|[$#]?\{[^{}]*\}`)
+
+// A log statement and the problems found with it.
+type LintResult struct {
+	analysis.LogStatement
+	Issues []LintIssue `json:"issues,omitempty"`
+}
+
+type LintIssue struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Finds every log statement under the roots and checks whether the logs
+// it writes could be mapped back to it. Messages are judged by the text
+// that is searched for when mapping, the literal start of the message.
+func Lint(roots []string) []LintResult {
+	results := []LintResult{}
+	seen := map[string]bool{}
+	for _, root := range roots {
+		for _, s := range analysis.LogStatements(analysisSource(root)) {
+			key := fmt.Sprintf("%s:%d", filepath.Clean(s.Path), s.Line)
+			if !seen[key] {
+				seen[key] = true
+				results = append(results, LintResult{LogStatement: s})
+			}
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Path != results[j].Path {
+			return results[i].Path < results[j].Path
+		}
+		return results[i].Line < results[j].Line
+	})
+
+	// Literal text of every message, searched terms match anywhere in it
+	literals := make([]string, len(results))
+	for i, r := range results {
+		literals[i] = placeholder.ReplaceAllString(r.Message, "\x00")
+	}
+
+	for i := range results {
+		r := &results[i]
+		if r.Message == "" {
+			r.Issues = append(r.Issues, LintIssue{ruleDynamic.ID, "The message is not a string literal"})
+			continue
+		}
+		words := len(strings.Fields(placeholder.ReplaceAllString(r.Message, " ")))
+		if holes := len(placeholder.FindAllString(r.Message, -1)); holes >= words {
+			r.Issues = append(r.Issues, LintIssue{ruleDynamic.ID, fmt.Sprintf("%d placeholders for %d words of text", holes, words)})
+			continue
+		}
+
+		term := searchTerm(r.Message)
+		if term == "" {
+			r.Issues = append(r.Issues, LintIssue{ruleGeneric.ID, "The message starts with a placeholder"})
+			continue
+		}
+		if len(term) < minSearchLength || !strings.Contains(term, " ") {
+			r.Issues = append(r.Issues, LintIssue{ruleGeneric.ID, fmt.Sprintf("Logs would be searched for by %q", term)})
+		}
+
+		others := []string{}
+		for j, literal := range literals {
+			if j != i && strings.Contains(literal, term) {
+				others = append(others, fmt.Sprintf("%s:%d", results[j].Path, results[j].Line))
+			}
+		}
+		if len(others) > 0 {
+			r.Issues = append(r.Issues, LintIssue{ruleAmbiguous.ID, fmt.Sprintf("%q also matches %s", term, strings.Join(others, ", "))})
+		}
+	}
+	return results
+}
+
+// Returns the text logs of the message are searched for by: its literal
+// start, up to the first placeholder, with anything after a colon left out.
+func searchTerm(msg string) string {
+	if loc := placeholder.FindStringIndex(msg); loc != nil {
+		msg = msg[:loc[0]]
+	}
+	return parseOutDynamics(msg, true)
+}
+
+// Returns the number of issues found.
+func LintIssues(results []LintResult) int {
+	n := 0
+	for _, r := range results {
+		n += len(r.Issues)
+	}
+	return n
+}
+
+// Writes one line per issue, or per statement when all is set.
+func WriteLintText(w io.Writer, results []LintResult, all bool) {
+	for _, r := range results {
+		if all {
+			fmt.Fprintf(w, "%s:%d\t%s\t%s\t%s\n", r.Path, r.Line, r.Level, r.Function, describeStatement(r.LogStatement))
+		}
+		for _, issue := range r.Issues {
+			fmt.Fprintf(w, "%s:%d: %s: %s\n", r.Path, r.Line, issue.Rule, issue.Message)
+		}
+	}
+	fmt.Fprintf(w, "%d issues in %d log statements\n", LintIssues(results), len(results))
+}
+
+// Writes every statement with its issues as JSON.
+func WriteLintJSON(w io.Writer, results []LintResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(results); err != nil {
+		return fmt.Errorf("error writing JSON: %v", err)
+	}
+	return nil
+}
+
+// Writes the issues as a SARIF 2.1.0 log, the format code scanning
+// tools read results from.
+func WriteLintSARIF(w io.Writer, results []LintResult) error {
+	type text struct {
+		Text string `json:"text"`
+	}
+	type rule struct {
+		ID               string `json:"id"`
+		ShortDescription text   `json:"shortDescription"`
+	}
+	type location struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region struct {
+				StartLine int `json:"startLine"`
+				EndLine   int `json:"endLine,omitempty"`
+			} `json:"region"`
+		} `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   text       `json:"message"`
+		Locations []location `json:"locations"`
+	}
+
+	rules := []rule{}
+	for _, r := range lintRules {
+		rules = append(rules, rule{r.ID, text{r.Description}})
+	}
+	found := []result{}
+	for _, r := range results {
+		for _, issue := range r.Issues {
+			var loc location
+			loc.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(r.Path)
+			loc.PhysicalLocation.Region.StartLine = r.Line
+			if r.EndLine > r.Line {
+				loc.PhysicalLocation.Region.EndLine = r.EndLine
+			}
+			found = append(found, result{issue.Rule, "warning", text{issue.Message}, []location{loc}})
+		}
+	}
+
+	sarif := map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []any{map[string]any{
+			"tool":    map[string]any{"driver": map[string]any{"name": "vlsa", "rules": rules}},
+			"results": found,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sarif); err != nil {
+		return fmt.Errorf("error writing SARIF: %v", err)
+	}
+	return nil
+}
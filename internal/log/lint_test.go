@@ -0,0 +1,76 @@
+package log
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"auth.go":   "package svc\n\nfunc login() {\n\tlog.Println(\"User login successful\")\n\tlog.Printf(\"%s: %v\", user, err)\n\tlog.Info(\"done\")\n\tlog.Infof(\"Session created for user %s\", id)\n}\n",
+		"legacy.go": "package svc\n\nfunc oldLogin() {\n\tlog.Println(\"User login successful\")\n}\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results := Lint([]string{root})
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 log statements, got %+v", results)
+	}
+	rules := map[int]string{}
+	for _, r := range results {
+		if filepath.Base(r.Path) != "auth.go" {
+			continue
+		}
+		for _, issue := range r.Issues {
+			rules[r.Line] += issue.Rule
+		}
+	}
+	expected := map[int]string{4: "ambiguous-message", 5: "dynamic-message", 6: "generic-message"}
+	for line, rule := range expected {
+		if rules[line] != rule {
+			t.Errorf("Expected line %d to be flagged %s, got %q", line, rule, rules[line])
+		}
+	}
+	if rules[7] != "" {
+		t.Errorf("Expected the session log to pass, got %q", rules[7])
+	}
+	if LintIssues(results) != 4 {
+		t.Errorf("Expected 4 issues, got %d", LintIssues(results))
+	}
+
+	var sarif strings.Builder
+	if err := WriteLintSARIF(&sarif, results); err != nil {
+		t.Fatal(err)
+	}
+	var parsed struct {
+		Version string
+		Runs    []struct {
+			Results []struct {
+				RuleID string
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(sarif.String()), &parsed); err != nil || parsed.Version != "2.1.0" || len(parsed.Runs) != 1 || len(parsed.Runs[0].Results) != 4 {
+		t.Errorf("Unexpected SARIF %s", sarif.String())
+	}
+}
+
+func TestSearchTerm(t *testing.T) {
+	for msg, term := range map[string]string{
+		"Session created for user %s": "Session created for user",
+		"error reading config: %v":    "error reading config",
+		"Loaded {count} rules":        "Loaded",
+	} {
+		if got := searchTerm(msg); got != term {
+			t.Errorf("Expected %q to be searched as %q, got %q", msg, term, got)
+		}
+	}
+}
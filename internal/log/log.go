@@ -2,10 +2,8 @@ package log
 
 import (
 	"bufio"
-	"encoding/csv"
 	"fmt"
 	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -16,7 +14,16 @@ import (
 type Log struct {
 	Time              time.Time
 	Service           string
+	Host              string
+	Level             string
 	Message           string
+	TraceID           string
+	SpanID            string
+	Caller            string            // file:line of the logging call when the logger records it
+	Attributes        map[string]string // Any other fields found in the log
+	Raw               string            // The log as it appeared in the file
+	Stack             []StackFrame      // Frames of a panicking or dumped goroutine, innermost first
+	Missing           []SourceMapping   // Log statements expected after this log that never showed up
 	Sources           []SourceMapping
 	SelectedSourceIdx int // Track which source index is currently selected
 }
@@ -24,15 +31,56 @@ type Log struct {
 type SourceMapping struct {
 	Path           string
 	Line           int
+	Function       string          // Function the line is in, when known
+	Type           string          // Type the function is a method of
+	Package        string          // Package or namespace of the file
+	Callers        []SourceMapping // Call sites of the logging wrapper the line is in
+	Changed        bool            // Whether the line or its function changed since the --since revision
+	Module         string          // Module path and version, for sources in a dependency
+	moduleDir      string
 	DisplayMessage string
 	SourceCode     string
 }
 
 var searchCache = map[string][]SourceMapping{}
 
+// Searches are cached per source root since the same message
+// can map to different code in different services.
+func cacheKey(root, sm string) string {
+	return root + "\x00" + sm
+}
+
+// Options controlling how logs are processed.
+type Options struct {
+	// Name of the parser to use, detected from the file when empty.
+	Format string
+	// Directory to search for the source of each service's logs.
+	// The empty service name is the default root for every other service,
+	// the working directory is searched when no root is configured.
+	SourceRoots map[string]string
+	// Whether each log is mapped against the revision that was deployed
+	// when it was written rather than the working tree.
+	Deployed bool
+	// Timeline of deployments, used for logs without a version attribute.
+	Deploys []Deploy
+	// Revision whose changes up to HEAD are marked on the logs they touch.
+	Since string
+	// Whether the modules required by each root's go.mod are searched
+	// too, after the root itself.
+	Dependencies bool
+}
+
+// Returns the directory the source for the service lives in.
+func (o Options) sourceRoot(service string) string {
+	if root, ok := o.SourceRoots[service]; ok {
+		return root
+	}
+	return o.SourceRoots[""]
+}
+
 // Processes logs at the provided file path.
 // Gives progress updates and sends the logs to the provided channel.
-func ProcessLogs(fp string, uChan chan LogProcessingMsg) {
+func ProcessLogs(fp string, opts Options, uChan chan LogProcessingMsg) {
 	// If a log file is provided, open it and read the logs
 	file, err := os.Open(fp)
 	if err != nil {
@@ -47,13 +95,17 @@ func ProcessLogs(fp string, uChan chan LogProcessingMsg) {
 	}
 	defer file.Close()
 
-	// If the file is a csv, parse it out with the csv package else, just plain text
+	// Exported logs usually arrive compressed so unwrap those first
+	br, name, parseErr := decompress(file, fp)
+
 	var logs []Log
-	var parseErr error
-	if fp[len(fp)-4:] == ".csv" {
-		logs, parseErr = parseCSVLogsWithError(file)
-	} else {
-		logs, parseErr = parsePlainTextLogsWithError(file)
+	if parseErr == nil {
+		var p Parser
+		p, parseErr = selectParser(br, name, opts.Format)
+		if parseErr == nil {
+			bus.LogChannel <- fmt.Sprintf("Parsing %s as %s logs", name, p.Name())
+			logs, parseErr = p.Parse(br)
+		}
 	}
 
 	if parseErr != nil {
@@ -69,10 +121,34 @@ func ProcessLogs(fp string, uChan chan LogProcessingMsg) {
 
 	bus.LogChannel <- fmt.Sprintf("Successfully parsed %d logs", len(logs))
 
+	// Every format spells its levels differently
+	for i := range logs {
+		logs[i].Level = NormalizeLevel(logs[i].Level)
+	}
+
+	// Container log files are named after the container that wrote them
+	if container, pod := containerFromPath(name); container != "" {
+		for i := range logs {
+			if logs[i].Service != "" {
+				continue
+			}
+			logs[i].Service = container
+			if logs[i].Attributes == nil {
+				logs[i].Attributes = map[string]string{}
+			}
+			logs[i].Attributes["pod"] = pod
+		}
+	}
+
 	// Map sources to logs
 	for i := range logs {
-		// TODO: mapping of sources to logs
-		sourceMapLog(&logs[i])
+		sourceMapLog(&logs[i], opts)
+		annotateSymbols(logs[i].Sources)
+		// Only working trees have changes to diff against
+		root := opts.sourceRoot(logs[i].Service)
+		if _, ok := treeFor(root).(dirTree); opts.Since != "" && ok {
+			markChanged(logs[i].Sources, opts.Since, root)
+		}
 
 		uChan <- LogProcessingMsg{
 			Progress: (i) * 100 / len(logs),
@@ -80,6 +156,8 @@ func ProcessLogs(fp string, uChan chan LogProcessingMsg) {
 		}
 	}
 
+	inferMissing(logs)
+
 	uChan <- LogProcessingMsg{
 		Progress: 100,
 		Logs:     logs,
@@ -88,85 +166,68 @@ func ProcessLogs(fp string, uChan chan LogProcessingMsg) {
 	return
 }
 
-func parseCSVLogs(file *os.File) []Log {
-	// Parse CSV file
-	logs := []Log{}
-	csvReader := csv.NewReader(file)
-	records, err := csvReader.ReadAll()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading CSV file: %v\n", err)
-		os.Exit(1)
-	}
-
-	for _, record := range records {
-		if len(record) < 2 {
-			continue // Skip malformed lines
+// Maps source files to logs. Source locations recorded by the logger
+// come first, followed by the locations found searching for the message.
+func sourceMapLog(l *Log, opts Options) {
+	root := opts.sourceRoot(l.Service)
+	// Older logs are searched for in the code that wrote them
+	if rev := opts.revision(*l, root); rev != "" {
+		if repo, _, ok := gitSpec(root); ok {
+			root = repo
 		}
-
-		// Time is provided as 2025-06-19T03:40:54.794Z
-		time, err := time.Parse("2006-01-02T15:04:05.000Z", record[0])
-		if err != nil {
-			continue
+		root = revisionPath(rev, root)
+	}
+	addMappedRoot(root)
+
+	// Access logs have no message to search for, the request
+	// path is mapped to the handler of its route instead
+	if method, path := l.Attributes["method"], l.Attributes["path"]; path != "" {
+		key := cacheKey(root, method+" "+path)
+		sources, found := searchCache[key]
+		if !found {
+			sources = routeSources(method, path, root)
+			searchCache[key] = sources
 		}
-		l := Log{
-			Time:    time,
-			Service: record[2],
-			Message: record[3],
-			Sources: []SourceMapping{}, // Sources are added later
+		if len(sources) > 0 {
+			l.Sources = sources
+			return
 		}
+	}
 
-		logs = append(logs, l)
+	// Goroutine stacks already say where they were, every frame
+	// found under the root becomes a source to step through
+	if len(l.Stack) > 0 {
+		if sources := stackSources(l.Stack, root); len(sources) > 0 {
+			l.Sources = sources
+			return
+		}
 	}
 
-	return logs
-}
+	searchSourceMapLog(l, root)
 
-func parseCSVLogsWithError(file *os.File) ([]Log, error) {
-	// Parse CSV file
-	logs := []Log{}
-	csvReader := csv.NewReader(file)
-	records, err := csvReader.ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf("error reading CSV file: %v", err)
+	// Messages kept in constants are logged where the constant is used
+	l.Sources = followIndirection(l.Sources, root)
+	// and messages in logging helpers are logged where the helper is called
+	l.Sources = resolveWrappers(l.Sources, root)
+	// Libraries log too, their source is searched after the root's own
+	if opts.Dependencies {
+		l.Sources = appendDependencySources(l.Sources, dependencySources(l.Message, root), root)
 	}
 
-	for _, record := range records {
-		if len(record) < 4 {
-			continue // Skip malformed lines
-		}
+	// Ambiguous messages are narrowed down by the module the logger is named after
+	if logger := firstAttribute(l.Attributes, loggerAttributes...); logger != "" {
+		l.Sources = rankByLogger(l.Sources, logger)
+	}
 
-		// Time is provided as 2025-06-19T03:40:54.794Z
-		time, err := time.Parse("2006-01-02T15:04:05.000Z", record[0])
-		if err != nil {
-			continue
-		}
-		l := Log{
-			Time:    time,
-			Service: record[2],
-			Message: record[3],
-			Sources: []SourceMapping{}, // Sources are added later
+	if l.Caller != "" {
+		if direct := callerSources(l.Caller, root, firstAttribute(l.Attributes, "CODE_FUNC", "code.function", "code.function.name")); len(direct) > 0 {
+			l.Sources = mergeSources(direct, l.Sources)
 		}
-
-		logs = append(logs, l)
 	}
-
-	return logs, nil
-}
-
-func parsePlainTextLogsWithError(file *os.File) ([]Log, error) {
-	return nil, fmt.Errorf("plain text log parsing not implemented yet")
-}
-
-func parsePlainTextLogs(file *os.File) []Log {
-	logs := []Log{}
-	// TODO: Implement parsing for plain text logs
-	panic("Plain text log parsing not implemented yet")
-
-	return logs
 }
 
 // Maps source files to logs based on the log message.
-func sourceMapLog(l *Log) {
+func searchSourceMapLog(l *Log, root string) {
 	sm := l.Message
 	// JSON or any part of the message after a colon in a
 	// log message is likely to be highly dynamic and not
@@ -178,18 +239,18 @@ func sourceMapLog(l *Log) {
 		return
 	} else {
 		// Check if we have already searched for this message
-		if sources, found := searchCache[sm]; found {
+		if sources, found := searchCache[cacheKey(root, sm)]; found {
 			bus.LogChannel <- fmt.Sprintf("Using cached source mapping for log message: %s", sm)
 			l.Sources = sources
 			return
 		}
 
-		sources := rg(sm)
+		sources := rg(sm, root)
 		// Cache the sources for this message
-		searchCache[sm] = sources
+		searchCache[cacheKey(root, sm)] = sources
 		if len(sources) == 0 {
 			sm := parseOutDynamics(l.Message, false)
-			sources = rg(sm)
+			sources = rg(sm, root)
 			if len(sources) == 0 {
 				l.Sources = []SourceMapping{{Path: "", Line: 0, DisplayMessage: "No source mapping found for this log message..."}}
 				return
@@ -203,25 +264,25 @@ func sourceMapLog(l *Log) {
 			// so we will try again with the full message, if that fails
 			// (ie. has 0 or greater than equal to results) we will try without the
 			// colon dynamics of a message. We should cache whichever result we take
-			if fullMsg, found := searchCache[l.Message]; found {
+			if fullMsg, found := searchCache[cacheKey(root, l.Message)]; found {
 				l.Sources = fullMsg
 				return
 			}
 
 			sm := parseOutDynamics(l.Message, false)
-			if colonSources, found := searchCache[sm]; found {
+			if colonSources, found := searchCache[cacheKey(root, sm)]; found {
 				l.Sources = colonSources
 				return
 			}
 
-			fullMsgSources := rg(l.Message)
+			fullMsgSources := rg(l.Message, root)
 			if len(fullMsgSources) < len(sources) && len(fullMsgSources) > 0 {
 				l.Sources = fullMsgSources
-				searchCache[l.Message] = fullMsgSources
+				searchCache[cacheKey(root, l.Message)] = fullMsgSources
 				return
-			} else if withColonSources := rg(sm); len(withColonSources) < len(sources) && len(withColonSources) < len(fullMsgSources) && len(withColonSources) > 0 {
+			} else if withColonSources := rg(sm, root); len(withColonSources) < len(sources) && len(withColonSources) < len(fullMsgSources) && len(withColonSources) > 0 {
 				l.Sources = withColonSources
-				searchCache[sm] = withColonSources
+				searchCache[cacheKey(root, sm)] = withColonSources
 				return
 			}
 
@@ -262,22 +323,21 @@ func parseOutDynamics(message string, colon bool) string {
 	return m
 }
 
-func rg(sm string) []SourceMapping {
-	cmd := exec.Command("rg", "--line-number", "-F", sm, "--glob", "!**/*.csv")
-
-	out, err := cmd.Output()
-	if err != nil {
-		if strings.Contains(err.Error(), "exit status 1") {
-			// This is expected if no matches are found, so we can ignore it
-			bus.LogChannel <- fmt.Sprintf("no matches found!")
-			return []SourceMapping{{Path: "", Line: 0, DisplayMessage: "No source mapping found for this log message..."}}
-		} else {
-			fmt.Fprintf(os.Stderr, "Error running command: %v\n%s\n\n\n%s", err, string(out), "Do you have ripgrep installed? It is required for source mapping.")
-			os.Exit(1)
-		}
+func rg(sm string, root string) []SourceMapping {
+	out, found := rgSearch([]string{"-F", sm}, root)
+	if !found {
+		// This is expected if no matches are found, so we can ignore it
+		bus.LogChannel <- fmt.Sprintf("no matches found!")
+		return []SourceMapping{{Path: "", Line: 0, DisplayMessage: "No source mapping found for this log message..."}}
 	}
 
-	return parseRGOutput(string(out))
+	return parseRGOutput(out)
+}
+
+// Searches the source root with ripgrep's pattern arguments and returns
+// its path:line:text output. Reports false when nothing matched.
+func rgSearch(patternArgs []string, root string) (string, bool) {
+	return treeFor(root).search(patternArgs)
 }
 
 func parseRGOutput(lines string) []SourceMapping {
@@ -285,34 +345,29 @@ func parseRGOutput(lines string) []SourceMapping {
 
 	scanner := bufio.NewScanner(strings.NewReader(lines))
 	for scanner.Scan() {
-		line := scanner.Text()
+		// Paths searched at a revision lead with its commit
+		rev, line := SplitRevision(scanner.Text())
 		segments := strings.SplitN(line, ":", 3)
 		if len(segments) < 3 {
 			continue // Skip malformed lines
 		}
+		segments[0] = revisionPath(rev, segments[0])
 		lineNum, err := strconv.Atoi(segments[1])
 		if err != nil {
 			continue // Skip malformed lines
 		}
 
-		f, err := os.OpenFile(segments[0], os.O_RDONLY, 0644)
+		// Files that can't be read are still shown, without their code
+		message := "File found!"
+		source, err := readSource(segments[0])
 		if err != nil {
-			bus.LogChannel <- fmt.Sprintf("Error opening source file %s: %v\n", segments[0], err)
-			continue // Skip files that cannot be opened
-		}
-
-		defer f.Close()
-		// Read the all source code from the file
-		source := ""
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			source += scanner.Text() + "\n"
+			message = fmt.Sprintf("File found, but it could not be read: %v", err)
 		}
 
 		sources = append(sources, SourceMapping{
 			Path:           segments[0],
 			Line:           lineNum,
-			DisplayMessage: "File found!",
+			DisplayMessage: message,
 			SourceCode:     source,
 		})
 
@@ -320,6 +375,11 @@ func parseRGOutput(lines string) []SourceMapping {
 	return sources
 }
 
+// Reads all the source code from the file.
+func readSource(path string) (string, error) {
+	return treeOf(path).read(path)
+}
+
 type LogProcessingMsg struct {
 	Progress int
 	Logs     []Log
@@ -0,0 +1,170 @@
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func init() {
+	RegisterParser(logfmtParser{})
+}
+
+// Parses logfmt lines as written by go-kit, logrus and slog's text handler
+// (ts=... level=error msg="failed to get user" user_id=42).
+type logfmtParser struct{}
+
+func (logfmtParser) Name() string { return "logfmt" }
+
+func (logfmtParser) Sniff(name string, head []string) float64 {
+	if len(head) == 0 {
+		return 0
+	}
+
+	matches := 0
+	for _, line := range head {
+		if !strings.Contains(line, "=") {
+			continue
+		}
+		pairs := parseLogfmt(line)
+		if len(pairs) < 2 {
+			continue
+		}
+		known := 0
+		for _, p := range pairs {
+			switch p.key {
+			case "ts", "time", "level", "lvl", "msg", "message", "caller":
+				known++
+			}
+		}
+		if known > 0 {
+			matches++
+		}
+	}
+	return 0.75 * float64(matches) / float64(len(head))
+}
+
+func (logfmtParser) Parse(file io.Reader) ([]Log, error) {
+	logs := []Log{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		logs = append(logs, parseLogfmtLine(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading log file: %v", err)
+	}
+
+	return logs, nil
+}
+
+func parseLogfmtLine(line string) Log {
+	l := Log{Raw: line, Attributes: map[string]string{}, Sources: []SourceMapping{}}
+	for _, p := range parseLogfmt(line) {
+		switch p.key {
+		case "ts", "time":
+			if t, ok := parseTime(p.value); ok {
+				l.Time = t
+				continue
+			}
+		case "level", "lvl":
+			l.Level = p.value
+			continue
+		case "msg", "message":
+			l.Message = p.value
+			continue
+		case "caller":
+			l.Caller = p.value
+			continue
+		case "host", "hostname":
+			l.Host = p.value
+			continue
+		case "service", "app":
+			l.Service = p.value
+			continue
+		case "trace_id", "traceID", "trace.id":
+			l.TraceID = p.value
+			continue
+		case "span_id", "spanID", "span.id":
+			l.SpanID = p.value
+			continue
+		}
+		l.Attributes[p.key] = p.value
+	}
+	// Lines without a message are still kept so nothing goes missing
+	// from the table, they just won't be source mapped
+	if l.Message == "" && len(l.Attributes) == 0 {
+		l.Message = line
+	}
+	return l
+}
+
+type logfmtPair struct {
+	key   string
+	value string
+}
+
+// Splits a logfmt line into its key value pairs. Values may be quoted,
+// in which case \" \\ \n \t escapes are honoured. Keys without a value
+// are given an empty value.
+func parseLogfmt(line string) []logfmtPair {
+	pairs := []logfmtPair{}
+	i := 0
+	for i < len(line) {
+		// Skip whitespace between pairs
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' {
+			i++
+		}
+		key := line[start:i]
+		if key == "" {
+			i++
+			continue
+		}
+		if i >= len(line) || line[i] != '=' {
+			pairs = append(pairs, logfmtPair{key: key})
+			continue
+		}
+		i++ // Skip the =
+
+		if i < len(line) && line[i] == '"' {
+			var value strings.Builder
+			i++
+			for i < len(line) && line[i] != '"' {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+					switch line[i] {
+					case 'n':
+						value.WriteByte('\n')
+					case 't':
+						value.WriteByte('\t')
+					default:
+						value.WriteByte(line[i])
+					}
+				} else {
+					value.WriteByte(line[i])
+				}
+				i++
+			}
+			i++ // Skip the closing quote
+			pairs = append(pairs, logfmtPair{key: key, value: value.String()})
+			continue
+		}
+
+		start = i
+		for i < len(line) && line[i] != ' ' {
+			i++
+		}
+		pairs = append(pairs, logfmtPair{key: key, value: line[start:i]})
+	}
+	return pairs
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Attributes structured loggers record the logger or module name in.
+var loggerAttributes = []string{"target", "logger", "logger_name", "loggerName", "log.logger", "otel.scope.name"}
+
+// Converts a logger name into the paths, relative to the top of a
+// project, of the files that conventionally declare it:
+//
+//	sensor::rules::rule_engine       -> src/rules/rule_engine.rs, src/rules/rule_engine/mod.rs
+//	com.acme.identity.IdentityService -> com/acme/identity/IdentityService.java
+//	app.services.user                -> app/services/user.py, app/services/user/__init__.py
+func loggerPaths(name string) []string {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "__main__" || name == "root" {
+		return nil
+	}
+
+	// Rust module paths, the crate is named in Cargo.toml so only
+	// the modules below it say where the file is
+	if strings.Contains(name, "::") {
+		modules := strings.Split(name, "::")[1:]
+		if len(modules) == 0 {
+			return []string{"src/lib.rs", "src/main.rs"}
+		}
+		path := "src/" + strings.Join(modules, "/")
+		return []string{path + ".rs", path + "/mod.rs"}
+	}
+
+	parts := strings.Split(name, ".")
+	last := parts[len(parts)-1]
+
+	// Java and Kotlin loggers are named after their class,
+	// nested classes live in the file of the outer class
+	if r := []rune(last); len(parts) > 1 && unicode.IsUpper(r[0]) {
+		parts[len(parts)-1], _, _ = strings.Cut(last, "$")
+		path := strings.Join(parts, "/")
+		return []string{path + ".java", path + ".kt"}
+	}
+
+	// Python loggers are named after their module with __name__
+	path := strings.Join(parts, "/")
+	return []string{path + ".py", path + "/__init__.py"}
+}
+
+// Scores how well the source path matches the logger's files. A path
+// ending in one of the files scores by how much of it matched, a path
+// in the same directory as one of the files scores less.
+func loggerScore(path string, candidates []string) int {
+	path = "/" + filepath.ToSlash(filepath.Clean(path))
+	score := 0
+	for _, c := range candidates {
+		if strings.HasSuffix(path, "/"+c) {
+			score = max(score, 100+strings.Count(c, "/"))
+			continue
+		}
+		if dir := filepath.ToSlash(filepath.Dir(c)); dir != "." && strings.HasSuffix(filepath.ToSlash(filepath.Dir(path)), "/"+dir) {
+			score = max(score, strings.Count(dir, "/")+1)
+		}
+	}
+	return score
+}
+
+// Orders the sources found for a log so the ones in the module its
+// logger is named after come first. Sources are left in their
+// original order when the logger's files aren't among them.
+func rankByLogger(sources []SourceMapping, logger string) []SourceMapping {
+	candidates := loggerPaths(logger)
+	if len(candidates) == 0 || len(sources) < 2 {
+		return sources
+	}
+
+	// Searched sources are shared through the cache so sort a copy
+	ranked := append([]SourceMapping{}, sources...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return loggerScore(ranked[i].Path, candidates) > loggerScore(ranked[j].Path, candidates)
+	})
+	return ranked
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoggerPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"sensor::rules::rule_engine", []string{"src/rules/rule_engine.rs", "src/rules/rule_engine/mod.rs"}},
+		{"sensor", []string{"sensor.py", "sensor/__init__.py"}},
+		{"com.acme.identity.IdentityService$Cache", []string{"com/acme/identity/IdentityService.java", "com/acme/identity/IdentityService.kt"}},
+		{"app.services.user", []string{"app/services/user.py", "app/services/user/__init__.py"}},
+		{"__main__", nil},
+	}
+
+	for _, tt := range tests {
+		if got := loggerPaths(tt.name); !slices.Equal(got, tt.expected) {
+			t.Errorf("loggerPaths(%q) = %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestRankByLogger(t *testing.T) {
+	searched := []SourceMapping{
+		{Path: "crates/api/src/handlers.rs", Line: 10},
+		{Path: "crates/engine/src/rules/loader.rs", Line: 20},
+		{Path: "crates/engine/src/rules/rule_engine.rs", Line: 1482},
+	}
+
+	ranked := rankByLogger(searched, "sensor::rules::rule_engine")
+	if ranked[0].Line != 1482 || ranked[1].Line != 20 || ranked[2].Line != 10 {
+		t.Errorf("Unexpected ranking %+v", ranked)
+	}
+	if searched[0].Line != 10 {
+		t.Errorf("Expected the searched sources to be left alone, got %+v", searched)
+	}
+
+	if unranked := rankByLogger(searched, "com.acme.Other"); unranked[0].Line != 10 {
+		t.Errorf("Expected the order to be kept without a match, got %+v", unranked)
+	}
+}
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"vlsa/internal/analysis"
+)
+
+// How many of the following logs are searched for the next one of the same request.
+const missingWindow = 50
+
+// Finds the log statements that should have fired after each log but
+// didn't. When the next log of the same request comes from a different
+// function, execution left the first log's function, so the statements
+// it would have logged on its way to returning were skipped, which
+// hints at where something failed. Statements that log later in the
+// request were only delayed by a call that logged itself.
+func inferMissing(logs []Log) {
+	order := make([]int, len(logs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return logs[order[a]].Time.Before(logs[order[b]].Time)
+	})
+
+	// Debug logs are usually turned off, so they are only expected
+	// from services that were seen writing them
+	verbose := map[string]bool{}
+	for _, l := range logs {
+		if l.Level == LevelDebug || l.Level == LevelTrace {
+			verbose[l.Service] = true
+		}
+	}
+
+	for n, i := range order {
+		a := &logs[i]
+		if len(a.Sources) == 0 || !strings.HasSuffix(a.Sources[0].Path, ".go") {
+			continue
+		}
+		from := a.Sources[0]
+		fn, ok := analysis.EnclosingFunc(from.Path, from.SourceCode, from.Line)
+		if !ok {
+			continue
+		}
+
+		later := []Log{}
+		for _, j := range order[n+1 : min(n+1+missingWindow, len(order))] {
+			if SameRequest(*a, logs[j]) {
+				later = append(later, logs[j])
+			}
+		}
+		if len(later) == 0 {
+			continue // The stream ended, nothing can be said about what came next
+		}
+		if b := later[0]; len(b.Sources) > 0 && b.Sources[0].Path == from.Path && fn.Start <= b.Sources[0].Line && b.Sources[0].Line <= fn.End {
+			continue // Still in the same function
+		}
+
+		for _, s := range fn.LogsAfter(from.Line) {
+			if analysis.IsVerbose(s.Level) && !verbose[a.Service] {
+				continue
+			}
+			// The function may have called one that logged and carried on
+			if loggedLater(s, later) {
+				continue
+			}
+			a.Missing = append(a.Missing, SourceMapping{
+				Path:           s.Path,
+				Line:           s.Line,
+				Function:       s.Function,
+				DisplayMessage: fmt.Sprintf("Expected, not seen: %q", s.Message),
+				SourceCode:     from.SourceCode,
+			})
+		}
+	}
+}
+
+// Reports whether any of the logs was written by the statement.
+func loggedLater(s analysis.LogStatement, logs []Log) bool {
+	for _, l := range logs {
+		if len(l.Sources) > 0 && l.Sources[0].Path == s.Path && s.Line <= l.Sources[0].Line && l.Sources[0].Line <= max(s.Line, s.EndLine) {
+			return true
+		}
+	}
+	return false
+}
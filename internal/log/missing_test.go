@@ -0,0 +1,39 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInferMissing(t *testing.T) {
+	src := "package main\n\nfunc save() error {\n\tlog.Info(\"saving user\")\n\tif err := db.Save(); err != nil {\n\t\treturn err\n\t}\n\tlog.Info(\"user saved\")\n\treturn nil\n}\n\nfunc handle() {\n\tlog.Error(\"request failed\")\n}\n"
+	at := func(line int) []SourceMapping {
+		return []SourceMapping{{Path: "main.go", Line: line, SourceCode: src}}
+	}
+	start := time.Date(2025, 6, 19, 3, 39, 0, 0, time.UTC)
+	logs := []Log{
+		{Time: start.Add(time.Second), Service: "api", Message: "request failed", Sources: at(13)},
+		{Time: start, Service: "api", Message: "saving user", Sources: at(4)},
+		{Time: start.Add(2 * time.Second), Service: "worker", Message: "saving user", Sources: at(4)},
+	}
+	inferMissing(logs)
+
+	if len(logs[1].Missing) != 1 || logs[1].Missing[0].Line != 8 {
+		t.Errorf("Expected the saved log to be missing, got %+v", logs[1].Missing)
+	}
+	// The worker's stream ends, so nothing is expected
+	if len(logs[0].Missing) != 0 || len(logs[2].Missing) != 0 {
+		t.Errorf("Expected nothing else to be missing, got %+v %+v", logs[0].Missing, logs[2].Missing)
+	}
+
+	// save calls into code that logs and then logs itself again
+	logs = []Log{
+		{Time: start, Service: "api", Message: "saving user", Sources: at(4)},
+		{Time: start.Add(time.Second), Service: "api", Message: "request failed", Sources: at(13)},
+		{Time: start.Add(2 * time.Second), Service: "api", Message: "user saved", Sources: at(8)},
+	}
+	inferMissing(logs)
+	if len(logs[0].Missing) != 0 {
+		t.Errorf("Expected the saved log seen later not to be missing, got %+v", logs[0].Missing)
+	}
+}
@@ -0,0 +1,195 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterParser(otlpParser{})
+}
+
+// Parses OpenTelemetry OTLP/JSON log exports, as written by the
+// collector's file exporter. Files hold one or more export requests.
+type otlpParser struct{}
+
+type otlpExport struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []otlpKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []struct {
+			Scope struct {
+				Name string `json:"name"`
+			} `json:"scope"`
+			// Kept raw so each record can be shown as it was exported
+			LogRecords []json.RawMessage `json:"logRecords"`
+		} `json:"scopeLogs"`
+	} `json:"resourceLogs"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityText         string         `json:"severityText"`
+	SeverityNumber       int            `json:"severityNumber"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes"`
+	TraceID              string         `json:"traceId"`
+	SpanID               string         `json:"spanId"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue"`
+	BoolValue   *bool    `json:"boolValue"`
+	IntValue    *string  `json:"intValue"`
+	DoubleValue *float64 `json:"doubleValue"`
+	BytesValue  *string  `json:"bytesValue"`
+	ArrayValue  *struct {
+		Values []otlpAnyValue `json:"values"`
+	} `json:"arrayValue"`
+	KvlistValue *struct {
+		Values []otlpKeyValue `json:"values"`
+	} `json:"kvlistValue"`
+}
+
+// Renders the value the way it would have been logged.
+func (v otlpAnyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != nil:
+		return *v.IntValue
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	case v.BytesValue != nil:
+		return *v.BytesValue
+	case v.ArrayValue != nil:
+		values := []string{}
+		for _, av := range v.ArrayValue.Values {
+			values = append(values, av.String())
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case v.KvlistValue != nil:
+		values := []string{}
+		for _, kv := range v.KvlistValue.Values {
+			values = append(values, kv.Key+"="+kv.Value.String())
+		}
+		return "{" + strings.Join(values, ", ") + "}"
+	}
+	return ""
+}
+
+// OTLP severity numbers come in groups of four per level.
+var otlpSeverities = []string{"trace", "debug", "info", "warning", "error", "fatal"}
+
+func (otlpParser) Name() string { return "otlp" }
+
+func (otlpParser) Sniff(name string, head []string) float64 {
+	for _, line := range head {
+		if strings.Contains(line, `"resourceLogs"`) {
+			return 0.98
+		}
+	}
+	return 0
+}
+
+func (otlpParser) Parse(file io.Reader) ([]Log, error) {
+	logs := []Log{}
+	decoder := json.NewDecoder(file)
+	for {
+		var export otlpExport
+		err := decoder.Decode(&export)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading OTLP file: %v", err)
+		}
+
+		for _, rl := range export.ResourceLogs {
+			resource := otlpAttributes(rl.Resource.Attributes)
+			for _, sl := range rl.ScopeLogs {
+				for _, raw := range sl.LogRecords {
+					var lr otlpLogRecord
+					if err := json.Unmarshal(raw, &lr); err != nil {
+						continue
+					}
+					l := Log{
+						Raw:        string(raw),
+						Service:    resource["service.name"],
+						Host:       resource["host.name"],
+						Level:      strings.ToLower(lr.SeverityText),
+						Message:    lr.Body.String(),
+						TraceID:    lr.TraceID,
+						SpanID:     lr.SpanID,
+						Attributes: otlpAttributes(lr.Attributes),
+						Sources:    []SourceMapping{},
+					}
+					if l.Level == "" && lr.SeverityNumber > 0 {
+						l.Level = otlpSeverities[min((lr.SeverityNumber-1)/4, len(otlpSeverities)-1)]
+					}
+
+					ts := lr.TimeUnixNano
+					if ts == "" || ts == "0" {
+						ts = lr.ObservedTimeUnixNano
+					}
+					if ns, err := strconv.ParseInt(ts, 10, 64); err == nil && ns > 0 {
+						l.Time = time.Unix(0, ns).UTC()
+					}
+
+					// Resource attributes apply to every record but the record's own win
+					for k, v := range resource {
+						if _, ok := l.Attributes[k]; !ok {
+							l.Attributes[k] = v
+						}
+					}
+					if sl.Scope.Name != "" {
+						l.Attributes["otel.scope.name"] = sl.Scope.Name
+					}
+
+					// Code attributes were renamed in newer semantic conventions
+					file := firstAttribute(l.Attributes, "code.filepath", "code.file.path")
+					if file != "" {
+						l.Caller = file
+						if line := firstAttribute(l.Attributes, "code.lineno", "code.line.number"); line != "" {
+							l.Caller += ":" + line
+						}
+					}
+					logs = append(logs, l)
+				}
+			}
+		}
+	}
+
+	return logs, nil
+}
+
+func otlpAttributes(kvs []otlpKeyValue) map[string]string {
+	attrs := map[string]string{}
+	for _, kv := range kvs {
+		attrs[kv.Key] = kv.Value.String()
+	}
+	return attrs
+}
+
+// Returns the value of the first attribute that is set.
+func firstAttribute(attrs map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := attrs[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,244 @@
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterParser(goPanicParser{})
+}
+
+// Parses the output of a crashed Go program. Panics and goroutine
+// dumps become one log per goroutine with its stack, every other
+// line is read like a plain text log.
+type goPanicParser struct{}
+
+// A single call in a goroutine's stack.
+type StackFrame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+var (
+	goroutineHeader = regexp.MustCompile(`^goroutine (\d+)(?: [^\[]*)?\[([^\]]*)\]:$`)
+	stackFileLine   = regexp.MustCompile(`^\t(.+):(\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+func (goPanicParser) Name() string { return "gopanic" }
+
+func (goPanicParser) Sniff(name string, head []string) float64 {
+	for _, line := range head {
+		if isPanicStart(line) || goroutineHeader.MatchString(line) {
+			return 0.6
+		}
+	}
+	return 0
+}
+
+func (goPanicParser) Parse(file io.Reader) ([]Log, error) {
+	return parseText(file)
+}
+
+func isPanicStart(line string) bool {
+	return strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ")
+}
+
+// Reads a text log, turning any panics and goroutine dumps
+// found along the way into logs with stacks.
+func parseText(file io.Reader) ([]Log, error) {
+	logs := []Log{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var panicLog *Log        // Panic waiting for the stack of the goroutine that panicked
+	var current *Log         // Goroutine whose frames are being read
+	seen := map[string]int{} // Stacks already in this dump, by their frames
+	function := ""           // Function of the frame whose file line comes next
+
+	// Adds the goroutine to the logs unless the same stack was
+	// already seen, then it is only counted
+	finish := func() {
+		if current == nil {
+			return
+		}
+		key := stackKey(current.Stack)
+		if i, ok := seen[key]; ok && len(current.Stack) > 0 {
+			dup := &logs[i]
+			count, _ := strconv.Atoi(dup.Attributes["goroutines"])
+			dup.Attributes["goroutines"] = strconv.Itoa(count + 1)
+			dup.Attributes["goroutine"] += "," + current.Attributes["goroutine"]
+		} else {
+			seen[key] = len(logs)
+			logs = append(logs, *current)
+		}
+		current = nil
+		function = ""
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if m := goroutineHeader.FindStringSubmatch(line); m != nil {
+			finish()
+			l := Log{
+				Message:    fmt.Sprintf("goroutine %s [%s]", m[1], m[2]),
+				Raw:        line,
+				Attributes: map[string]string{"goroutine": m[1], "state": m[2], "goroutines": "1"},
+				Sources:    []SourceMapping{},
+			}
+			if len(logs) > 0 {
+				l.Time, l.Service, l.Host = logs[len(logs)-1].Time, logs[len(logs)-1].Service, logs[len(logs)-1].Host
+			}
+			// The first goroutine after a panic is the one that panicked
+			if panicLog != nil {
+				l.Message, l.Level = panicLog.Message, LevelFatal
+				l.Raw = panicLog.Raw + "\n\n" + line
+				l.Time = panicLog.Time
+				panicLog = nil
+			}
+			current = &l
+			continue
+		}
+
+		if current != nil {
+			if m := stackFileLine.FindStringSubmatch(line); m != nil {
+				n, _ := strconv.Atoi(m[2])
+				current.Stack = append(current.Stack, StackFrame{Function: function, File: m[1], Line: n})
+				current.Raw += "\n" + line
+				function = ""
+				continue
+			}
+			if line != "" && !strings.HasPrefix(line, "\t") && !isPanicStart(line) && !goroutineHeader.MatchString(line) && (strings.HasSuffix(line, ")") || strings.HasPrefix(line, "created by ") || strings.HasPrefix(line, "...")) {
+				if !strings.HasPrefix(line, "...") { // ...additional frames elided...
+					function = stackFunction(line)
+				}
+				current.Raw += "\n" + line
+				continue
+			}
+			finish()
+		}
+
+		if panicLog != nil {
+			// [signal SIGSEGV ...] and nested panics belong to the panic
+			if line != "" && (strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "[")) {
+				panicLog.Raw += "\n" + line
+				continue
+			}
+			if line == "" {
+				continue
+			}
+			// A panic without any stack is still worth showing
+			logs = append(logs, *panicLog)
+			panicLog = nil
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		l := textLine(line)
+		if isPanicStart(l.Message) {
+			l.Level = LevelFatal
+			// Panics are written straight to stderr without a timestamp
+			if l.Time.IsZero() && len(logs) > 0 {
+				l.Time = logs[len(logs)-1].Time
+			}
+			panicLog = &l
+			seen = map[string]int{}
+			continue
+		}
+		logs = append(logs, l)
+	}
+	finish()
+	if panicLog != nil {
+		logs = append(logs, *panicLog)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading log file: %v", err)
+	}
+
+	return logs, nil
+}
+
+// Returns the function called in a stack line like
+// main.(*Server).handle(0xc000010000, {0x1, 0x2}).
+func stackFunction(line string) string {
+	if f, ok := strings.CutPrefix(line, "created by "); ok {
+		if i := strings.Index(f, " in goroutine "); i > 0 {
+			f = f[:i]
+		}
+		return "created by " + f
+	}
+
+	// Strip the arguments, matching the last ) back to its (
+	depth := 0
+	for i := len(line) - 1; i >= 0; i-- {
+		switch line[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+func stackKey(stack []StackFrame) string {
+	var b strings.Builder
+	for _, f := range stack {
+		fmt.Fprintf(&b, "%s %s:%d\n", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+// Maps every frame of the stack to its source. Frames in the
+// runtime or in dependencies that aren't under the root are skipped.
+func stackSources(stack []StackFrame, root string) []SourceMapping {
+	sources := []SourceMapping{}
+	for i, f := range stack {
+		found := callerSources(fmt.Sprintf("%s:%d", f.File, f.Line), root, "")
+		if len(found) == 0 || !sameFrameFile(found[0].Path, f, root) {
+			continue
+		}
+		s := found[0]
+		s.Function = f.Function
+		s.DisplayMessage = fmt.Sprintf("Frame %d of %d: %s", i+1, len(stack), f.Function)
+		sources = append(sources, s)
+	}
+	return sources
+}
+
+// Stack paths are absolute so a file that only shares its name with the
+// frame's, like the runtime's panic.go, has to be in the same directory
+// too. Files at the top of the root can only be package main, and files
+// outside the root, like a local Go installation, are left out.
+func sameFrameFile(path string, f StackFrame, root string) bool {
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	if filepath.Dir(rel) == "." {
+		return filepath.Dir(f.File) == "." || strings.HasPrefix(f.Function, "main.")
+	}
+	return filepath.Base(filepath.Dir(path)) == filepath.Base(filepath.Dir(f.File))
+}
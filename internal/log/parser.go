@@ -0,0 +1,164 @@
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Number of lines handed to each parser when sniffing the log format.
+const sniffLines = 20
+
+// Parser turns the contents of a log file into Logs.
+// Parsers register themselves with RegisterParser so ProcessLogs can pick
+// the best one for a file without knowing about any specific format.
+type Parser interface {
+	// Name of the format, used to force a parser with --format.
+	Name() string
+	// Sniff reports how confident the parser is, from 0 to 1, that it can
+	// parse a file with the provided name whose first lines are head.
+	Sniff(name string, head []string) float64
+	// Parse reads every log in the file.
+	Parse(r io.Reader) ([]Log, error)
+}
+
+var parsers = []Parser{}
+
+// Adds a parser to the registry used for format detection.
+func RegisterParser(p Parser) {
+	parsers = append(parsers, p)
+}
+
+// Returns the names of every registered parser, sorted.
+func ParserNames() []string {
+	names := []string{}
+	for _, p := range parsers {
+		names = append(names, p.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Picks the parser for the log file. A forced format always wins,
+// otherwise every parser sniffs the head of the file and the most
+// confident one is used.
+func selectParser(br *bufio.Reader, name string, format string) (Parser, error) {
+	if format != "" {
+		for _, p := range parsers {
+			if p.Name() == format {
+				return p, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown log format %q, expected one of: %s", format, strings.Join(ParserNames(), ", "))
+	}
+
+	head := peekLines(br, sniffLines)
+
+	var best Parser
+	bestScore := 0.0
+	for _, p := range parsers {
+		if score := p.Sniff(name, head); score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("could not detect the log format, try --format with one of: %s", strings.Join(ParserNames(), ", "))
+	}
+	return best, nil
+}
+
+// Returns up to n lines from the start of the reader without consuming them.
+func peekLines(br *bufio.Reader, n int) []string {
+	buf, err := br.Peek(br.Size())
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil
+	}
+
+	lines := strings.Split(string(buf), "\n")
+	// The last line is likely cut off by the buffer so leave it out
+	if err == bufio.ErrBufferFull && len(lines) > 1 {
+		lines = lines[:len(lines)-1]
+	}
+
+	head := []string{}
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		head = append(head, line)
+		if len(head) == n {
+			break
+		}
+	}
+	return head
+}
+
+// Layouts tried, in order, when a log timestamp is parsed.
+var timeLayouts = []string{
+	"2006-01-02T15:04:05.000Z",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"02/Jan/2006:15:04:05 -0700",
+	time.Stamp,
+	time.StampMicro,
+}
+
+// Parses the timestamp formats commonly found in logs.
+func parseTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Structured loggers often end up wrapped by another format, like JSON
+// written to a container's stdout or a Datadog CSV export. Parses the
+// message as JSON or logfmt so the real message and fields can be used.
+func parseEmbedded(msg string) (Log, bool) {
+	trimmed := strings.TrimSpace(msg)
+	if strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}") {
+		return parseJSONLine(trimmed)
+	}
+	if strings.Contains(msg, "msg=") || strings.Contains(msg, "message=") {
+		return parseLogfmtLine(msg), true
+	}
+	return Log{}, false
+}
+
+// Fills in the log from the fields of its embedded log.
+// Fields already set on the outer log win.
+func mergeEmbedded(l *Log, inner Log) {
+	l.Message = inner.Message
+	if l.Time.IsZero() {
+		l.Time = inner.Time
+	}
+	for _, f := range []struct{ outer, inner *string }{
+		{&l.Service, &inner.Service},
+		{&l.Host, &inner.Host},
+		{&l.Level, &inner.Level},
+		{&l.TraceID, &inner.TraceID},
+		{&l.SpanID, &inner.SpanID},
+		{&l.Caller, &inner.Caller},
+	} {
+		if *f.outer == "" {
+			*f.outer = *f.inner
+		}
+	}
+	if l.Attributes == nil {
+		l.Attributes = map[string]string{}
+	}
+	for k, v := range inner.Attributes {
+		if _, ok := l.Attributes[k]; !ok {
+			l.Attributes[k] = v
+		}
+	}
+}
@@ -0,0 +1,447 @@
+package log
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelectParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+	}{
+		{"csv by extension", "logs.csv", "anything\n", "csv"},
+		{"csv by content", "/tmp/vlsa_upload_1.txt", "Date,Host,Service,Message\n\"2025-06-19T03:39:21.231Z\",\"i-04f\",\"pi\",\"Failed to get user by filters\"\n", "csv"},
+		{"json lines", "app.log", "{\"level\":\"info\",\"msg\":\"started\"}\n{\"level\":\"error\",\"msg\":\"failed\"}\n", "json"},
+		{"plain text", "app.log", "2025-06-19 03:39:21 Failed to get user\n", "text"},
+	}
+
+	for _, tt := range tests {
+		br := bufio.NewReader(strings.NewReader(tt.content))
+		p, err := selectParser(br, tt.file, "")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if p.Name() != tt.expected {
+			t.Errorf("%s: expected %s parser, got %s", tt.name, tt.expected, p.Name())
+		}
+	}
+}
+
+func TestSelectParserForced(t *testing.T) {
+	br := bufio.NewReader(strings.NewReader("{\"msg\":\"started\"}\n"))
+	p, err := selectParser(br, "logs.csv", "text")
+	if err != nil || p.Name() != "text" {
+		t.Errorf("Expected forced text parser, got %v %v", p, err)
+	}
+
+	if _, err := selectParser(br, "logs.csv", "nope"); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
+
+func TestJSONParser(t *testing.T) {
+	content := `{"timestamp":"2025-06-19T03:39:18.016963659+00:00","level":"WARN","fields":{"message":"real rule was found"},"target":"sensor::rules::rule_engine"}
+not json
+{"ts":1750304358.016,"msg":"failed to get user","service":"gw"}
+`
+	logs, err := jsonParser{}.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d", len(logs))
+	}
+	if logs[0].Message != "real rule was found" || logs[0].Time.IsZero() {
+		t.Errorf("Unexpected first log %+v", logs[0])
+	}
+	if logs[1].Message != "failed to get user" || logs[1].Service != "gw" || logs[1].Time.Year() != 2025 {
+		t.Errorf("Unexpected second log %+v", logs[1])
+	}
+}
+
+func TestTextParser(t *testing.T) {
+	logs, err := textParser{}.Parse(strings.NewReader("2025-06-19T03:39:21Z Failed to get user\n\nplain message\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d", len(logs))
+	}
+	if logs[0].Message != "Failed to get user" || logs[0].Time.IsZero() {
+		t.Errorf("Unexpected first log %+v", logs[0])
+	}
+	if logs[1].Message != "plain message" {
+		t.Errorf("Unexpected second log %+v", logs[1])
+	}
+}
+
+func TestLogfmtParser(t *testing.T) {
+	content := `ts=2025-06-19T03:39:21.231Z level=error msg="failed to get \"user\"" caller=service/user.go:42 user_id=42 retry
+time=2025-06-19T03:39:22Z lvl=info message=started
+`
+	if p, _ := selectParser(bufio.NewReader(strings.NewReader(content)), "app.log", ""); p == nil || p.Name() != "logfmt" {
+		t.Errorf("Expected logfmt to be detected, got %v", p)
+	}
+
+	logs, err := logfmtParser{}.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d", len(logs))
+	}
+
+	l := logs[0]
+	if l.Message != `failed to get "user"` || l.Level != "error" || l.Caller != "service/user.go:42" || l.Time.IsZero() {
+		t.Errorf("Unexpected first log %+v", l)
+	}
+	if l.Attributes["user_id"] != "42" {
+		t.Errorf("Expected user_id attribute, got %v", l.Attributes)
+	}
+	if _, ok := l.Attributes["retry"]; !ok {
+		t.Errorf("Expected bare key to be kept as an attribute, got %v", l.Attributes)
+	}
+	if logs[1].Message != "started" || logs[1].Level != "info" {
+		t.Errorf("Unexpected second log %+v", logs[1])
+	}
+}
+
+func TestSyslogParsers(t *testing.T) {
+	rfc3164 := `<34>Oct 11 22:14:15 web-1 identity[4123]: Failed to get user by id
+Jun  9 03:40:54 web-2 cron: job started
+`
+	if p, _ := selectParser(bufio.NewReader(strings.NewReader(rfc3164)), "syslog", ""); p == nil || p.Name() != "rfc3164" {
+		t.Errorf("Expected rfc3164 to be detected, got %v", p)
+	}
+	logs, err := rfc3164Parser{}.Parse(strings.NewReader(rfc3164))
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d %v", len(logs), err)
+	}
+	l := logs[0]
+	if l.Host != "web-1" || l.Service != "identity" || l.Level != "critical" || l.Message != "Failed to get user by id" {
+		t.Errorf("Unexpected rfc3164 log %+v", l)
+	}
+	if l.Attributes["procid"] != "4123" || l.Attributes["facility"] != "auth" {
+		t.Errorf("Unexpected rfc3164 attributes %v", l.Attributes)
+	}
+	if logs[1].Service != "cron" || logs[1].Time.Day() != 9 {
+		t.Errorf("Unexpected rfc3164 log without priority %+v", logs[1])
+	}
+
+	rfc5424 := `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application"] An application event log entry...
+<11>1 2003-10-11T22:14:16Z web-1 identity 4123 - - Failed to get user by id
+`
+	if p, _ := selectParser(bufio.NewReader(strings.NewReader(rfc5424)), "syslog", ""); p == nil || p.Name() != "rfc5424" {
+		t.Errorf("Expected rfc5424 to be detected, got %v", p)
+	}
+	logs, err = rfc5424Parser{}.Parse(strings.NewReader(rfc5424))
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d %v", len(logs), err)
+	}
+	l = logs[0]
+	if l.Host != "mymachine.example.com" || l.Service != "evntslog" || l.Level != "notice" || l.Message != "An application event log entry..." {
+		t.Errorf("Unexpected rfc5424 log %+v", l)
+	}
+	if l.Attributes["exampleSDID@32473.eventSource"] != "Application" || l.Attributes["msgid"] != "ID47" {
+		t.Errorf("Unexpected rfc5424 attributes %v", l.Attributes)
+	}
+	if logs[1].Attributes["procid"] != "4123" || logs[1].Level != "error" {
+		t.Errorf("Unexpected rfc5424 log %+v", logs[1])
+	}
+
+	// Signed priorities aren't priorities and mustn't crash detection
+	for _, line := range []string{"<-1>Oct 11 22:14:15 web-1 app: hi", "<+1>1 2003-10-11T22:14:15Z web-1 app - - - hi"} {
+		l := Log{Attributes: map[string]string{}}
+		if _, ok := parsePriority(line, &l); ok {
+			t.Errorf("Expected %q to have no priority, got level %q", line, l.Level)
+		}
+		selectParser(bufio.NewReader(strings.NewReader(line+"\n")), "syslog", "")
+	}
+}
+
+func TestCSVParser(t *testing.T) {
+	content := "Date,Host,Service,Message\r\n" +
+		"2025-06-19T03:39:21.231Z, i-04f ,identity,\"Failed to get user\nby id\"\r\n" +
+		"2025-06-19T03:39:22.000Z,i-05a,gw,request finished\n"
+	logs, err := csvParser{}.Parse(strings.NewReader(content))
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d %v", len(logs), err)
+	}
+	// Rows are kept exactly as they were written
+	if logs[0].Raw != "2025-06-19T03:39:21.231Z, i-04f ,identity,\"Failed to get user\nby id\"" || logs[0].Message != "Failed to get user\nby id" {
+		t.Errorf("Unexpected csv log %q %q", logs[0].Raw, logs[0].Message)
+	}
+	if logs[1].Raw != "2025-06-19T03:39:22.000Z,i-05a,gw,request finished" || logs[1].Service != "gw" {
+		t.Errorf("Unexpected csv log %+v", logs[1])
+	}
+}
+
+func TestContainerParsers(t *testing.T) {
+	docker := `{"log":"Failed to get user ","stream":"stderr","time":"2024-06-19T03:39:21.231234567Z"}
+{"log":"by id\n","stream":"stderr","time":"2024-06-19T03:39:21.231234567Z","attrs":{"tag":"identity"}}
+{"log":"{\"level\":\"info\",\"msg\":\"started\"}\n","stream":"stdout","time":"2024-06-19T03:39:22Z"}
+`
+	if p, _ := selectParser(bufio.NewReader(strings.NewReader(docker)), "container-json.log", ""); p == nil || p.Name() != "docker" {
+		t.Errorf("Expected docker to be detected, got %v", p)
+	}
+	logs, err := dockerParser{}.Parse(strings.NewReader(docker))
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d %v", len(logs), err)
+	}
+	if logs[0].Message != "Failed to get user by id" || logs[0].Service != "identity" || logs[0].Attributes["stream"] != "stderr" {
+		t.Errorf("Unexpected joined docker log %+v", logs[0])
+	}
+	if logs[1].Message != "started" || logs[1].Attributes["stream"] != "stdout" {
+		t.Errorf("Expected embedded JSON to be unwrapped, got %+v", logs[1])
+	}
+
+	cri := `2024-06-19T03:39:21.231234567Z stderr P Failed to get user
+2024-06-19T03:39:21.231234567Z stderr F  by id
+2024-06-19T03:39:22.000000000Z stdout F level=info msg="cache warmed"
+`
+	if p, _ := selectParser(bufio.NewReader(strings.NewReader(cri)), "0.log", ""); p == nil || p.Name() != "cri" {
+		t.Errorf("Expected cri to be detected, got %v", p)
+	}
+	logs, err = criParser{}.Parse(strings.NewReader(cri))
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d %v", len(logs), err)
+	}
+	if logs[0].Message != "Failed to get user by id" || logs[0].Attributes["stream"] != "stderr" {
+		t.Errorf("Unexpected joined cri log %+v", logs[0])
+	}
+	if logs[1].Message != "cache warmed" || logs[1].Level != "info" {
+		t.Errorf("Expected embedded logfmt to be unwrapped, got %+v", logs[1])
+	}
+
+	// Partial lines are joined per stream, and the last one is kept at the end of the file
+	interleaved := `2024-06-19T03:39:21.000000000Z stderr P Failed to get
+2024-06-19T03:39:21.100000000Z stdout F request finished
+2024-06-19T03:39:21.200000000Z stderr F  user by id
+2024-06-19T03:39:21.300000000Z stdout P shutting
+`
+	logs, err = criParser{}.Parse(strings.NewReader(interleaved))
+	if err != nil || len(logs) != 3 {
+		t.Fatalf("Expected 3 logs, got %d %v", len(logs), err)
+	}
+	if logs[0].Message != "request finished" || logs[1].Message != "Failed to get user by id" || logs[2].Message != "shutting" || logs[2].Attributes["stream"] != "stdout" {
+		t.Errorf("Unexpected interleaved cri logs %+v", logs)
+	}
+	logs, err = dockerParser{}.Parse(strings.NewReader(`{"log":"Failed to get ","stream":"stderr","time":"2024-06-19T03:39:21Z"}
+{"log":"started\n","stream":"stdout","time":"2024-06-19T03:39:22Z"}
+{"log":"user by id","stream":"stderr","time":"2024-06-19T03:39:23Z"}`))
+	if err != nil || len(logs) != 2 || logs[0].Message != "started" || logs[1].Message != "Failed to get user by id" || logs[1].Time.Second() != 23 {
+		t.Errorf("Unexpected interleaved docker logs %+v %v", logs, err)
+	}
+
+	kubectl := `[pod/identity-7d9f-abc12/identity] 2024-06-19T03:39:21.231234567Z Failed to get user by id
+[pod/gw-5c8b-xyz34/gateway] 2024-06-19T03:39:22.000000000Z request finished
+`
+	if p, _ := selectParser(bufio.NewReader(strings.NewReader(kubectl)), "logs.txt", ""); p == nil || p.Name() != "kubectl" {
+		t.Errorf("Expected kubectl to be detected, got %v", p)
+	}
+	logs, err = kubectlParser{}.Parse(strings.NewReader(kubectl))
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d %v", len(logs), err)
+	}
+	if logs[0].Service != "identity" || logs[0].Attributes["pod"] != "identity-7d9f-abc12" || logs[0].Message != "Failed to get user by id" || logs[0].Time.IsZero() {
+		t.Errorf("Unexpected kubectl log %+v", logs[0])
+	}
+}
+
+func TestContainerFromPath(t *testing.T) {
+	tests := []struct {
+		path      string
+		container string
+		pod       string
+	}{
+		{"/var/log/pods/default_identity-7d9f-abc12_0a1b2c/identity/0.log", "identity", "identity-7d9f-abc12"},
+		{"/var/log/containers/identity-7d9f-abc12_default_identity-0123456789abcdef.log", "identity", "identity-7d9f-abc12"},
+		{"logs.csv", "", ""},
+	}
+	for _, tt := range tests {
+		container, pod := containerFromPath(tt.path)
+		if container != tt.container || pod != tt.pod {
+			t.Errorf("%s: expected %s %s, got %s %s", tt.path, tt.container, tt.pod, container, pod)
+		}
+	}
+}
+
+func TestJournaldParser(t *testing.T) {
+	content := `{"__CURSOR":"s=1","__REALTIME_TIMESTAMP":"1750304361231234","_HOSTNAME":"web-1","PRIORITY":"3","SYSLOG_IDENTIFIER":"identity","_SYSTEMD_UNIT":"identity.service","MESSAGE":"Failed to get user by id","CODE_FILE":"../src/identity/user.c","CODE_LINE":"42","CODE_FUNC":"get_user"}
+{"__REALTIME_TIMESTAMP":"1750304362000000","_SYSTEMD_UNIT":"gw.service","PRIORITY":"6","MESSAGE":[104,105]}
+`
+	if p, _ := selectParser(bufio.NewReader(strings.NewReader(content)), "journal.json", ""); p == nil || p.Name() != "journald" {
+		t.Errorf("Expected journald to be detected, got %v", p)
+	}
+	logs, err := journaldParser{}.Parse(strings.NewReader(content))
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d %v", len(logs), err)
+	}
+	l := logs[0]
+	if l.Service != "identity" || l.Host != "web-1" || l.Level != "error" || l.Message != "Failed to get user by id" || l.Time.Year() != 2025 {
+		t.Errorf("Unexpected journald log %+v", l)
+	}
+	if l.Caller != "../src/identity/user.c:42" || l.Attributes["CODE_FUNC"] != "get_user" {
+		t.Errorf("Expected code location to be kept, got %s %v", l.Caller, l.Attributes)
+	}
+	if _, ok := l.Attributes["__CURSOR"]; ok {
+		t.Errorf("Expected journal internals to be dropped, got %v", l.Attributes)
+	}
+	if logs[1].Service != "gw" || logs[1].Message != "hi" || logs[1].Level != "info" {
+		t.Errorf("Unexpected second journald log %+v", logs[1])
+	}
+}
+
+func TestCallerSources(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "src", "identity"), 0755)
+	os.WriteFile(filepath.Join(root, "src", "identity", "user.c"), []byte("int a;\nint b;\nlog(\"Failed to get user by id\");\n"), 0644)
+	os.MkdirAll(filepath.Join(root, "src", "billing"), 0755)
+	os.WriteFile(filepath.Join(root, "src", "billing", "user.c"), []byte("int a;\n"), 0644)
+
+	sources := callerSources("/build/project/src/identity/user.c:3", root, "get_user")
+	if len(sources) != 1 {
+		t.Fatalf("Expected 1 source, got %+v", sources)
+	}
+	if sources[0].Path != filepath.Join(root, "src", "identity", "user.c") || sources[0].Line != 3 || sources[0].SourceCode == "" {
+		t.Errorf("Unexpected source %+v", sources[0])
+	}
+
+	if sources := callerSources("missing.c:3", root, ""); len(sources) != 0 {
+		t.Errorf("Expected no sources for a missing file, got %+v", sources)
+	}
+
+	// Files that only share the name with the recorded one aren't it
+	if paths := resolveCallerPath("/build/svc/user.c", root); len(paths) != 0 {
+		t.Errorf("Expected no files for a path matching only by name, got %v", paths)
+	}
+	if paths := resolveCallerPath("user.c", root); len(paths) != 0 {
+		t.Errorf("Expected no files for an ambiguous file name, got %v", paths)
+	}
+
+	merged := mergeSources(sources, []SourceMapping{{Path: "", DisplayMessage: "No source mapping found for this log message..."}})
+	if len(merged) != len(sources) {
+		t.Errorf("Expected placeholder mappings to be dropped, got %+v", merged)
+	}
+}
+
+func TestOTLPParser(t *testing.T) {
+	content := `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"identity"}},{"key":"host.name","value":{"stringValue":"web-1"}}]},"scopeLogs":[{"scope":{"name":"identity.users"},"logRecords":[{"timeUnixNano":"1750304361231000000","severityNumber":17,"severityText":"ERROR","body":{"stringValue":"Failed to get user by id"},"traceId":"5b8efff798038103d269b633813fc60c","spanId":"eee19b7ec3c1b174","attributes":[{"key":"user.id","value":{"intValue":"42"}},{"key":"code.filepath","value":{"stringValue":"identity/users.go"}},{"key":"code.lineno","value":{"intValue":"87"}}]}]}]}]}
+{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"gw"}}]},"scopeLogs":[{"logRecords":[{"observedTimeUnixNano":"1750304362000000000","severityNumber":9,"body":{"kvlistValue":{"values":[{"key":"event","value":{"stringValue":"started"}}]}}}]}]}]}
+`
+	if p, _ := selectParser(bufio.NewReader(strings.NewReader(content)), "otlp.json", ""); p == nil || p.Name() != "otlp" {
+		t.Errorf("Expected otlp to be detected, got %v", p)
+	}
+	logs, err := otlpParser{}.Parse(strings.NewReader(content))
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d %v", len(logs), err)
+	}
+
+	l := logs[0]
+	if l.Service != "identity" || l.Host != "web-1" || l.Level != "error" || l.Message != "Failed to get user by id" || l.Time.Year() != 2025 {
+		t.Errorf("Unexpected otlp log %+v", l)
+	}
+	if l.TraceID != "5b8efff798038103d269b633813fc60c" || l.SpanID != "eee19b7ec3c1b174" {
+		t.Errorf("Expected trace context to be kept, got %s %s", l.TraceID, l.SpanID)
+	}
+	if l.Caller != "identity/users.go:87" || l.Attributes["user.id"] != "42" || l.Attributes["otel.scope.name"] != "identity.users" {
+		t.Errorf("Unexpected otlp attributes %s %v", l.Caller, l.Attributes)
+	}
+	if logs[1].Service != "gw" || logs[1].Level != "info" || logs[1].Message != "{event=started}" || logs[1].Time.IsZero() {
+		t.Errorf("Unexpected second otlp log %+v", logs[1])
+	}
+}
+
+func TestNormalizeLevel(t *testing.T) {
+	cases := map[string]string{
+		"WARNING":       LevelWarn,
+		"err":           LevelError,
+		" Critical ":    LevelFatal,
+		"informational": LevelInfo,
+		"30":            LevelInfo,
+		"50":            LevelError,
+		"60":            LevelFatal,
+		"custom":        "custom",
+	}
+	for in, want := range cases {
+		if got := NormalizeLevel(in); got != want {
+			t.Errorf("NormalizeLevel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGoPanicParser(t *testing.T) {
+	content := `2025-06-19T03:39:21Z starting server
+panic: runtime error: invalid memory address or nil pointer dereference
+[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x4a1b2c]
+
+goroutine 1 [running]:
+main.(*Server).handle(0x0, {0x6b2f40, 0xc000012345})
+	/home/build/app/cmd/web/main.go:42 +0x1f
+main.main()
+	/home/build/app/cmd/web/main.go:20 +0x25
+
+goroutine 7 [chan receive]:
+main.worker()
+	/home/build/app/cmd/web/main.go:60 +0x3d
+created by main.main in goroutine 1
+	/home/build/app/cmd/web/main.go:18 +0x4f
+
+goroutine 8 [chan receive]:
+main.worker()
+	/home/build/app/cmd/web/main.go:60 +0x3d
+created by main.main in goroutine 1
+	/home/build/app/cmd/web/main.go:18 +0x4f
+exit status 2
+`
+	if p, _ := selectParser(bufio.NewReader(strings.NewReader(content)), "app.log", ""); p == nil || p.Name() != "gopanic" {
+		t.Errorf("Expected gopanic to be detected, got %v", p)
+	}
+
+	logs, err := goPanicParser{}.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(logs) != 4 {
+		t.Fatalf("Expected 4 logs, got %d: %+v", len(logs), logs)
+	}
+
+	crash := logs[1]
+	if !strings.HasPrefix(crash.Message, "panic: runtime error") || crash.Level != LevelFatal || crash.Time.IsZero() {
+		t.Errorf("Unexpected panic log %+v", crash)
+	}
+	if len(crash.Stack) != 2 || crash.Stack[0].Function != "main.(*Server).handle" || crash.Stack[0].Line != 42 {
+		t.Errorf("Unexpected panic stack %+v", crash.Stack)
+	}
+	if !strings.Contains(crash.Raw, "SIGSEGV") {
+		t.Errorf("Expected the signal in the raw panic, got %q", crash.Raw)
+	}
+
+	// Goroutines 7 and 8 have the same stack
+	workers := logs[2]
+	if workers.Attributes["goroutines"] != "2" || workers.Attributes["goroutine"] != "7,8" || workers.Attributes["state"] != "chan receive" {
+		t.Errorf("Expected deduplicated workers, got %+v", workers.Attributes)
+	}
+	if len(workers.Stack) != 2 || workers.Stack[1].Function != "created by main.main" {
+		t.Errorf("Unexpected worker stack %+v", workers.Stack)
+	}
+	if logs[3].Message != "exit status 2" {
+		t.Errorf("Unexpected last log %+v", logs[3])
+	}
+
+	// Frames are mapped to the checkout by their path suffix
+	sources := stackSources(crash.Stack, "../..")
+	if len(sources) != 2 || sources[0].Line != 42 || sources[0].Function != "main.(*Server).handle" {
+		t.Errorf("Unexpected stack sources %+v", sources)
+	}
+	if runtime := stackSources([]StackFrame{{Function: "runtime.gopanic", File: "/usr/local/go/src/runtime/panic.go", Line: 1}}, "../.."); len(runtime) != 0 {
+		t.Errorf("Expected runtime frames to be skipped, got %+v", runtime)
+	}
+}
@@ -0,0 +1,148 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"vlsa/internal/analysis"
+)
+
+// Attributes loggers commonly tie the logs of a request together with.
+var requestAttributes = []string{"request_id", "req_id", "requestId", "request.id", "x_request_id", "correlation_id"}
+
+// Reports whether the two logs were likely written while handling the
+// same request, going by their trace or request IDs. Logs without any
+// IDs are taken to be the same request when the same process wrote them,
+// which can't be told for logs that name neither service nor host.
+func SameRequest(a, b Log) bool {
+	if a.TraceID != "" || b.TraceID != "" {
+		return a.TraceID == b.TraceID
+	}
+	idA, idB := firstAttribute(a.Attributes, requestAttributes...), firstAttribute(b.Attributes, requestAttributes...)
+	if idA != "" || idB != "" {
+		return idA == idB
+	}
+	if a.Service == "" && a.Host == "" {
+		return false
+	}
+	return a.Service == b.Service && a.Host == b.Host
+}
+
+// Returns the lines that ran between two sources in the same Go
+// function, along with the branches that could not have been taken.
+// Reports false when the sources aren't in the same Go function.
+func ExecutionPath(from, to SourceMapping) (analysis.ExecutionPath, bool) {
+	if !strings.HasSuffix(from.Path, ".go") || filepath.Clean(from.Path) != filepath.Clean(to.Path) || from.Line == to.Line {
+		return analysis.ExecutionPath{}, false
+	}
+	fn, ok := analysis.EnclosingFunc(to.Path, to.SourceCode, to.Line)
+	if !ok {
+		return analysis.ExecutionPath{}, false
+	}
+	return fn.PathBetween(from.Line, to.Line)
+}
+
+// A function on the call path between two logs.
+type CallStep struct {
+	SourceMapping      // Where in the function execution went on from
+	Returned      bool // Whether execution returned to the function rather than calling it
+}
+
+// Source roots that have been mapped, call paths are searched in their Go code.
+// Logs are mapped in the background while the TUI looks up call paths.
+var (
+	mappedRoots   = map[string]bool{}
+	mappedRootsMu sync.Mutex
+)
+
+func addMappedRoot(root string) {
+	mappedRootsMu.Lock()
+	defer mappedRootsMu.Unlock()
+	mappedRoots[root] = true
+}
+
+// Returns the source roots that have been mapped, sorted.
+func mappedRootList() []string {
+	mappedRootsMu.Lock()
+	defer mappedRootsMu.Unlock()
+	roots := []string{}
+	for root := range mappedRoots {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+var (
+	callPathCache = map[string][]CallStep{}
+	callPathMu    sync.Mutex
+)
+
+// Finds the shortest chain of calls from the function of one source to
+// the function of another, using a call graph of the Go code under the
+// source roots. The first and last steps are the sources' own functions.
+func CallPath(from, to SourceMapping) ([]CallStep, bool) {
+	if !strings.HasSuffix(from.Path, ".go") || !strings.HasSuffix(to.Path, ".go") {
+		return nil, false
+	}
+	// Call paths are looked up one at a time so each is only computed once
+	callPathMu.Lock()
+	defer callPathMu.Unlock()
+	key := fmt.Sprintf("%s:%d\x00%s:%d", from.Path, from.Line, to.Path, to.Line)
+	if steps, found := callPathCache[key]; found {
+		return steps, len(steps) > 0
+	}
+
+	steps := []CallStep{}
+	for _, root := range mappedRootList() {
+		ix := analysis.Load(analysisSource(root))
+		fromFn, ok := ix.FuncAt(from.Path, from.Line)
+		if !ok {
+			continue
+		}
+		toFn, ok := ix.FuncAt(to.Path, to.Line)
+		if !ok || fromFn.ID() == toFn.ID() {
+			continue
+		}
+		path, ok := ix.CallPath(fromFn, toFn)
+		if !ok {
+			continue
+		}
+
+		for i, s := range path {
+			source, err := readSource(s.Func.Path)
+			if err != nil {
+				continue
+			}
+			step := CallStep{
+				SourceMapping: SourceMapping{
+					Path:           s.Func.Path,
+					Line:           s.Line,
+					Function:       s.Func.String(),
+					Type:           strings.TrimPrefix(s.Func.Receiver, "*"),
+					Package:        s.Func.Package,
+					DisplayMessage: "Calls " + s.Func.String(),
+					SourceCode:     source,
+				},
+				Returned: s.Returned,
+			}
+			if s.Returned {
+				step.DisplayMessage = "Returns to " + s.Func.String()
+			}
+			// The ends of the path are where the logs were written
+			if i == 0 {
+				step.Line = from.Line
+			} else if i == len(path)-1 {
+				step.Line = to.Line
+			}
+			steps = append(steps, step)
+		}
+		break
+	}
+
+	callPathCache[key] = steps
+	return steps, len(steps) > 0
+}
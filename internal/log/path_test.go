@@ -0,0 +1,23 @@
+package log
+
+import "testing"
+
+func TestSameRequest(t *testing.T) {
+	tests := []struct {
+		a, b     Log
+		expected bool
+	}{
+		{Log{TraceID: "abc", Service: "gw"}, Log{TraceID: "abc", Service: "identity"}, true},
+		{Log{TraceID: "abc"}, Log{TraceID: "def"}, false},
+		{Log{Attributes: map[string]string{"request_id": "1"}}, Log{Attributes: map[string]string{"req_id": "1"}}, true},
+		{Log{Service: "gw", Host: "web-1"}, Log{Service: "gw", Host: "web-1"}, true},
+		{Log{Service: "gw", Host: "web-1"}, Log{Service: "gw", Host: "web-2"}, false},
+		// Plain text logs don't say which process wrote them
+		{Log{Message: "a"}, Log{Message: "b"}, false},
+	}
+	for _, tt := range tests {
+		if got := SameRequest(tt.a, tt.b); got != tt.expected {
+			t.Errorf("SameRequest(%+v, %+v) = %v, want %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"vlsa/internal/git"
+)
+
+// Sources read from a git revision rather than the working tree are
+// named the way git names blobs, <commit>:<path>, with the full commit
+// hash. Roots, paths from searches and paths handed to readSource all
+// carry the revision this way, so the rest of the mapping doesn't need
+// to know about it.
+var revisionPrefix = regexp.MustCompile(`^([0-9a-f]{40}):`)
+
+// Splits a path into the commit it is read from and the path itself.
+// The commit is empty for paths in the working tree.
+func SplitRevision(path string) (string, string) {
+	if m := revisionPrefix.FindStringSubmatch(path); m != nil {
+		return m[1], path[len(m[0]):]
+	}
+	return "", path
+}
+
+func revisionPath(rev, path string) string {
+	if rev == "" {
+		return path
+	}
+	if path == "" {
+		path = "."
+	}
+	return rev + ":" + path
+}
+
+// Attributes loggers record the version or commit of the build in.
+var versionAttributes = []string{
+	"git_sha", "git.sha", "git_commit", "git.commit", "commit", "commit_sha", "build_sha", "build.sha",
+	"vcs.revision", "revision", "service.version", "app.version", "version",
+}
+
+// Versions like v1.4.2-12-g3f2a9c1 or 1.4.2+3f2a9c1 end with a commit.
+var describedCommit = regexp.MustCompile(`(?:-g|\+)([0-9a-f]{7,40})$`)
+
+// A deployment of a commit, from a timeline of deployments.
+type Deploy struct {
+	Time    time.Time
+	Commit  string
+	Service string // Empty when every service was deployed
+}
+
+// Reads a deployment timeline with one timestamp and commit per line,
+// separated by a comma or whitespace and optionally followed by the
+// service that was deployed. Lines that don't start with a timestamp,
+// like headers and comments, are skipped.
+func ReadDeploys(path string) ([]Deploy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening deployment timeline: %v", err)
+	}
+	defer f.Close()
+
+	deploys := []Deploy{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.FieldsFunc(scanner.Text(), func(r rune) bool { return r == ',' || r == '\t' })
+		if len(fields) < 2 {
+			fields = strings.Fields(scanner.Text())
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		t, ok := parseTime(fields[0])
+		if !ok {
+			continue
+		}
+		d := Deploy{Time: t, Commit: strings.TrimSpace(fields[1])}
+		if len(fields) > 2 {
+			d.Service = strings.TrimSpace(fields[2])
+		}
+		deploys = append(deploys, d)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading deployment timeline: %v", err)
+	}
+	sort.SliceStable(deploys, func(i, j int) bool { return deploys[i].Time.Before(deploys[j].Time) })
+	return deploys, nil
+}
+
+// Commits that names resolve to in each root, or "" when they don't.
+var revisionCache = map[string]string{}
+
+// Resolves a commit name, like a tag or an abbreviated hash, in the git
+// repository holding the root to its full hash.
+func resolveRevision(root, name string) string {
+	if root == "" {
+		root = "."
+	}
+	key := cacheKey(root, name)
+	if rev, found := revisionCache[key]; found {
+		return rev
+	}
+	// Names come from logs and users, they must never be read as options
+	rev := ""
+	if !strings.HasPrefix(name, "-") {
+		out, err := exec.Command("git", "-C", root, "rev-parse", "--verify", "--quiet", "--end-of-options", name+"^{commit}").Output()
+		if err == nil {
+			rev = strings.TrimSpace(string(out))
+		}
+	}
+	revisionCache[key] = rev
+	return rev
+}
+
+// Picks the revision the log was written by: the commit in its version
+// attributes, or else the last deployment before it. Returns "" to map
+// against the working tree.
+func (o Options) revision(l Log, root string) string {
+	if !o.Deployed {
+		return ""
+	}
+	// Archives and revisions have no history to pick from, but the
+	// repository of a git: root does
+	if repo, _, ok := gitSpec(root); ok {
+		root = repo
+	} else if _, ok := treeFor(root).(dirTree); !ok {
+		return ""
+	}
+	for _, attr := range versionAttributes {
+		v := l.Attributes[attr]
+		if v == "" {
+			continue
+		}
+		if m := describedCommit.FindStringSubmatch(v); m != nil {
+			v = m[1]
+		}
+		if rev := resolveRevision(root, v); rev != "" {
+			return rev
+		}
+	}
+
+	commit := ""
+	for _, d := range o.Deploys {
+		if d.Time.After(l.Time) {
+			break
+		}
+		if d.Service == "" || d.Service == l.Service {
+			commit = d.Commit
+		}
+	}
+	if commit == "" {
+		return ""
+	}
+	return resolveRevision(root, commit)
+}
+
+// Searches the root at a revision with git grep, taking the same pattern
+// arguments as ripgrep. Returns <commit>:path:line:text lines with the
+// paths under the root, like ripgrep's.
+func gitGrep(patternArgs []string, rev, root string) (string, bool) {
+	dir, spec := revisionDir(root)
+	// ripgrep's regular expressions are closest to extended ones
+	mode := "-E"
+	if slices.Contains(patternArgs, "-F") {
+		mode = "-F"
+	}
+	args := []string{"-C", dir, "grep", "-n", "-I", mode}
+	for i := 0; i < len(patternArgs); i++ {
+		switch a := patternArgs[i]; {
+		case a == "-F":
+		case a == "-e" && i+1 < len(patternArgs):
+			args = append(args, "-e", patternArgs[i+1])
+			i++
+		case strings.HasPrefix(a, "-"):
+			args = append(args, a)
+		default:
+			args = append(args, "-e", a)
+		}
+	}
+	args = append(args, rev, "--", spec, ":!*.csv")
+
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", false
+	}
+
+	// Paths are relative to the root, like ripgrep's when it is given one
+	lines := []string{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+		path, rest, found := strings.Cut(strings.TrimPrefix(line, rev+":"), ":")
+		if !found {
+			continue
+		}
+		if root != "" {
+			path = filepath.Join(dir, path)
+		}
+		lines = append(lines, revisionPath(rev, path)+":"+rest)
+	}
+	return strings.Join(lines, "\n"), len(lines) > 0
+}
+
+// Reads a file as it was at a revision. Its directory may not exist
+// anymore, so git runs from the top of the repository.
+func gitShow(rev, path string) (string, error) {
+	top, name, err := git.RepoPath(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s at %s: %v", path, rev[:8], err)
+	}
+	out, err := exec.Command("git", "-C", top, "show", rev+":"+name).Output()
+	if err != nil {
+		return "", fmt.Errorf("error reading %s at %s: %v", path, rev[:8], err)
+	}
+	return string(out), nil
+}
+
+// Lists the files under the root at a revision.
+func gitListFiles(rev, root string) []string {
+	dir, spec := revisionDir(root)
+	out, err := exec.Command("git", "-C", dir, "ls-tree", "-r", "--name-only", rev, "--", spec).Output()
+	if err != nil {
+		return nil
+	}
+	files := []string{}
+	for _, f := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if f != "" {
+			if root != "" {
+				f = filepath.Join(dir, f)
+			}
+			files = append(files, revisionPath(rev, f))
+		}
+	}
+	return files
+}
+
+// Directories at a revision, like a vendor/ that was since removed, may
+// not exist anymore. Returns the closest directory of the root that does
+// for git to run in, and the root relative to it.
+func revisionDir(root string) (string, string) {
+	dir := root
+	if dir == "" {
+		dir = "."
+	}
+	spec := "."
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, spec
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ".", filepath.ToSlash(root)
+		}
+		spec = filepath.ToSlash(filepath.Join(filepath.Base(dir), spec))
+		dir = parent
+	}
+}
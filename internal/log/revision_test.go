@@ -0,0 +1,87 @@
+package log
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Creates a git repository and returns it along with a function that
+// commits a new version of a file, returning the commit's hash.
+func testGitRepo(t *testing.T) (string, func(name, src string) string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	gitRun := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	gitRun("init", "-q")
+	return root, func(name, src string) string {
+		os.WriteFile(filepath.Join(root, name), []byte(src), 0644)
+		gitRun("add", name)
+		gitRun("commit", "-q", "-m", "Update "+name)
+		return gitRun("rev-parse", "HEAD")
+	}
+}
+
+func TestDeployedRevision(t *testing.T) {
+	root, commit := testGitRepo(t)
+	path := filepath.Join(root, "pay.go")
+	old := commit("pay.go", "package pay\n\nfunc charge() {\n\tlog.Error(\"Payment failed for order\")\n}\n")
+	current := commit("pay.go", "package pay\n\n// Moved down\n\nfunc charge() {\n\tlog.Error(\"Charge declined\")\n}\n")
+
+	timeline := filepath.Join(t.TempDir(), "deploys.csv")
+	os.WriteFile(timeline, []byte("time,commit\n2025-06-12T00:00:00Z,"+current[:10]+"\n2025-06-09T00:00:00Z,"+old[:10]+",payments\n"), 0644)
+	deploys, err := ReadDeploys(timeline)
+	if err != nil || len(deploys) != 2 || deploys[0].Service != "payments" {
+		t.Fatalf("Unexpected timeline %+v %v", deploys, err)
+	}
+
+	opts := Options{Deployed: true, Deploys: deploys}
+	at := func(day int) time.Time { return time.Date(2025, 6, day, 10, 0, 0, 0, time.UTC) }
+	for _, c := range []struct {
+		l   Log
+		rev string
+	}{
+		{Log{Time: at(10), Service: "payments"}, old},
+		{Log{Time: at(10), Service: "orders"}, ""},
+		{Log{Time: at(13), Service: "payments"}, current},
+		{Log{Time: at(13), Attributes: map[string]string{"version": "v1.0.0-1-g" + old[:7]}}, old},
+	} {
+		if rev := opts.revision(c.l, root); rev != c.rev {
+			t.Errorf("Expected %+v to be mapped at %q, got %q", c.l, c.rev, rev)
+		}
+	}
+
+	// Searching at the old revision finds the message that was since changed
+	sources := rg("Payment failed for order", revisionPath(old, root))
+	if len(sources) != 1 || sources[0].Line != 4 || !strings.Contains(sources[0].SourceCode, "Payment failed") {
+		t.Fatalf("Unexpected sources %+v", sources)
+	}
+	if rev, file := SplitRevision(sources[0].Path); rev != old || file != path {
+		t.Errorf("Expected %s at %s, got %s at %s", path, old, file, rev)
+	}
+}
+
+func TestGitShowDeletedDirectory(t *testing.T) {
+	root, commit := testGitRepo(t)
+	os.MkdirAll(filepath.Join(root, "old"), 0755)
+	rev := commit("old/pay.go", "package old\n\nfunc charge() {\n\tlog.Error(\"Payment failed for order\")\n}\n")
+	os.RemoveAll(filepath.Join(root, "old"))
+
+	// The file is found at the revision and has to be readable there too
+	sources := rg("Payment failed for order", revisionPath(rev, root))
+	if len(sources) != 1 || !strings.Contains(sources[0].SourceCode, "func charge()") {
+		t.Fatalf("Unexpected sources in a deleted directory %+v", sources)
+	}
+}
@@ -0,0 +1,232 @@
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A route registered in the source, like http.HandleFunc("/api/logs/", handleLogDetail).
+type route struct {
+	Path    string
+	Line    int
+	Method  string // Empty when the route accepts any method
+	Pattern string
+	Handler string // Name of the handler function, empty for inline handlers
+}
+
+// Routes registered under each source root.
+var routeCache = map[string][]route{}
+
+// Lines that call something with a string starting with a slash,
+// narrowed down to route registrations by parseRouteLine.
+const routeSearch = `[\w.:@]+\s*\(\s*(?:value\s*=\s*|path\s*=\s*)?["'` + "`" + `](?:[A-Z]+ +)?/`
+
+var routeCall = regexp.MustCompile(`([\w.:@]+)\s*\(\s*(?:value\s*=\s*|path\s*=\s*)?["'` + "`" + `]((?:[A-Z]+ +)?/[^"'` + "`" + `]*)["'` + "`" + `]\s*(?:,\s*([\w.]+)\s*(\()?)?`)
+
+// Functions and annotations that register routes in common routers:
+// net/http, gorilla/mux, chi, gin, echo, express, flask, spring and laravel.
+var routeRegistrars = map[string]string{
+	"handle": "", "handlefunc": "", "handlerfunc": "", "route": "", "any": "", "all": "", "match": "", "add": "",
+	"get": "GET", "post": "POST", "put": "PUT", "patch": "PATCH", "delete": "DELETE", "head": "HEAD", "options": "OPTIONS",
+	"getmapping": "GET", "postmapping": "POST", "putmapping": "PUT", "patchmapping": "PATCH", "deletemapping": "DELETE", "requestmapping": "",
+}
+
+// HTTP clients share their method names with routers (http.Get).
+var httpClients = map[string]bool{"http": true, "client": true, "requests": true, "axios": true, "fetch": true}
+
+// Reads a route registration from a line of source.
+func parseRouteLine(text string) (route, bool) {
+	loc := routeCall.FindStringSubmatchIndex(text)
+	if loc == nil || isComment(text[:loc[0]]) {
+		return route{}, false
+	}
+	m := routeCall.FindStringSubmatch(text)
+
+	callee := strings.TrimPrefix(m[1], "@")
+	name := callee
+	receiver := ""
+	if i := strings.LastIndexAny(callee, ".:"); i >= 0 {
+		name, receiver = callee[i+1:], strings.Trim(callee[:i], ".:")
+	}
+	method, ok := routeRegistrars[strings.ToLower(name)]
+	if !ok || (method != "" && httpClients[strings.ToLower(receiver)]) {
+		return route{}, false
+	}
+
+	r := route{Pattern: m[2], Method: method}
+	// Go 1.22 patterns can start with a method, "GET /api/logs/{id}"
+	if before, after, found := strings.Cut(m[2], " "); found {
+		r.Method, r.Pattern = before, strings.TrimSpace(after)
+	}
+	// Handlers built by a call (http.StripPrefix(...)) or declared inline
+	// aren't named functions we can jump to
+	if m[3] != "" && m[4] == "" {
+		r.Handler = m[3][strings.LastIndex(m[3], ".")+1:]
+	}
+	return r, true
+}
+
+// Reports whether the text leading up to a match starts a comment.
+func isComment(prefix string) bool {
+	trimmed := strings.TrimSpace(prefix)
+	return strings.Contains(prefix, "//") || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "/*")
+}
+
+// Routes registered by tests would otherwise shadow the real ones.
+func isTestFile(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || strings.Contains(filepath.ToSlash(path), "/tests/")
+}
+
+// Scores how well a request path matches a route pattern. Literal segments
+// count more than parameters and full matches beat prefix matches.
+// Reports false when the pattern does not match the path.
+func matchRoute(pattern string, path string) (int, bool) {
+	patternSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(path, "/"), "/")
+	if strings.Trim(pattern, "/") == "" {
+		patternSegs = nil
+	}
+	if strings.Trim(path, "/") == "" {
+		pathSegs = nil
+	}
+
+	score := 0
+	for i, seg := range patternSegs {
+		// Wildcards that swallow the rest of the path
+		if seg == "*" || seg == "**" || strings.HasSuffix(seg, "...}") || strings.HasPrefix(seg, "*") {
+			return score + 1, true
+		}
+		if i >= len(pathSegs) {
+			return 0, false
+		}
+
+		switch {
+		case seg == pathSegs[i]:
+			score += 3
+		case isRouteParam(seg):
+			score += 1
+		default:
+			return 0, false
+		}
+	}
+
+	// A trailing slash only matches the path when it has one too
+	if len(pathSegs) == len(patternSegs) && (!strings.HasSuffix(pattern, "/") || strings.HasSuffix(path, "/") || pattern == "/") {
+		return score + 1000, true
+	}
+	// ServeMux style patterns ending in a slash match everything below them
+	if strings.HasSuffix(pattern, "/") {
+		return score, true
+	}
+	return 0, false
+}
+
+// Path parameters look like :id, {id}, <id> or <int:id> depending on the router.
+func isRouteParam(seg string) bool {
+	return strings.HasPrefix(seg, ":") ||
+		(strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")) ||
+		(strings.HasPrefix(seg, "<") && strings.HasSuffix(seg, ">"))
+}
+
+// Finds every route registered under the root.
+func findRoutes(root string) []route {
+	if routes, ok := routeCache[root]; ok {
+		return routes
+	}
+
+	routes := []route{}
+	out, _ := rgSearch([]string{"-e", routeSearch}, root)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		rev, line := SplitRevision(scanner.Text())
+		segments := strings.SplitN(line, ":", 3)
+		if len(segments) < 3 {
+			continue // Skip malformed lines
+		}
+		segments[0] = revisionPath(rev, segments[0])
+		lineNum, err := strconv.Atoi(segments[1])
+		if err != nil || isTestFile(segments[0]) {
+			continue // Skip malformed lines and tests
+		}
+		r, ok := parseRouteLine(segments[2])
+		if !ok {
+			continue
+		}
+		r.Path, r.Line = segments[0], lineNum
+		routes = append(routes, r)
+	}
+
+	routeCache[root] = routes
+	return routes
+}
+
+// Returns the routes that best match the request, several when the
+// same pattern is registered more than once.
+func matchRoutes(routes []route, method string, path string) []route {
+	best := []route{}
+	bestScore := -1
+	for _, r := range routes {
+		if r.Method != "" && method != "" && !strings.EqualFold(r.Method, method) {
+			continue
+		}
+		score, ok := matchRoute(r.Pattern, path)
+		if !ok || score < bestScore {
+			continue
+		}
+		if score > bestScore {
+			best, bestScore = []route{}, score
+		}
+		best = append(best, r)
+	}
+	return best
+}
+
+// Maps an access log to the handlers of the routes that served the request.
+// Each handler's definition is listed ahead of the line registering it.
+func routeSources(method string, rawPath string, root string) []SourceMapping {
+	path := rawPath
+	if u, err := url.ParseRequestURI(rawPath); err == nil {
+		path = u.Path
+	}
+
+	sources := []SourceMapping{}
+	for _, r := range matchRoutes(findRoutes(root), method, path) {
+		if r.Handler != "" {
+			for _, h := range handlerSources(r.Handler, root) {
+				if isTestFile(h.Path) {
+					continue
+				}
+				h.DisplayMessage = fmt.Sprintf("Handler %s for route %s (%s:%d)", r.Handler, r.Pattern, r.Path, r.Line)
+				sources = append(sources, h)
+			}
+		}
+		source, err := readSource(r.Path)
+		if err != nil {
+			continue
+		}
+		sources = append(sources, SourceMapping{
+			Path:           r.Path,
+			Line:           r.Line,
+			DisplayMessage: fmt.Sprintf("Route %s registered here", r.Pattern),
+			SourceCode:     source,
+		})
+	}
+	return mergeSources(nil, sources)
+}
+
+// Finds where a handler function is defined in Go, Python, JavaScript or PHP.
+func handlerSources(name string, root string) []SourceMapping {
+	pattern := `(func\s+(\([^)]*\)\s*)?|def\s+|function\s+)` + regexp.QuoteMeta(name) + `\s*\(`
+	out, found := rgSearch([]string{"-e", pattern}, root)
+	if !found {
+		return nil
+	}
+	return parseRGOutput(out)
+}
@@ -0,0 +1,96 @@
+package log
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAccessParser(t *testing.T) {
+	content := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /api/logs/3/source?source=1 HTTP/1.1" 200 2326 "http://localhost:8080/" "Mozilla/5.0" 0.123
+10.0.0.2 - - [10/Oct/2000:13:55:37 -0700] "POST /api/upload HTTP/1.1" 502 -
+`
+	if p, _ := selectParser(bufio.NewReader(strings.NewReader(content)), "access.log", ""); p == nil || p.Name() != "access" {
+		t.Errorf("Expected access to be detected, got %v", p)
+	}
+	logs, err := accessParser{}.Parse(strings.NewReader(content))
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d %v", len(logs), err)
+	}
+
+	l := logs[0]
+	if l.Attributes["method"] != "GET" || l.Attributes["path"] != "/api/logs/3/source?source=1" || l.Attributes["status"] != "200" {
+		t.Errorf("Unexpected request attributes %v", l.Attributes)
+	}
+	if l.Attributes["user_agent"] != "Mozilla/5.0" || l.Attributes["latency"] != "123ms" || l.Level != "info" || l.Time.IsZero() {
+		t.Errorf("Unexpected access log %+v", l)
+	}
+	if logs[1].Level != "error" || logs[1].Attributes["remote_addr"] != "10.0.0.2" {
+		t.Errorf("Unexpected common format log %+v", logs[1])
+	}
+}
+
+func TestParseRouteLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		ok      bool
+		method  string
+		pattern string
+		handler string
+	}{
+		{`	http.HandleFunc("/api/logs/", handleLogDetail) // For /api/logs/{id}/source`, true, "", "/api/logs/", "handleLogDetail"},
+		{`	mux.HandleFunc("GET /users/{id}", h.getUser)`, true, "GET", "/users/{id}", "getUser"},
+		{`router.get('/users/:id', function (req, res) {`, true, "GET", "/users/:id", ""},
+		{`@app.route("/login")`, true, "", "/login", ""},
+		{`	@GetMapping("/identity/{id}")`, true, "GET", "/identity/{id}", ""},
+		{`	resp, err := http.Get("/health")`, false, "", "", ""},
+		{`	http.Handle("/static/", http.StripPrefix("/static/", fs))`, true, "", "/static/", ""},
+	}
+	for _, tt := range tests {
+		r, ok := parseRouteLine(tt.line)
+		if ok != tt.ok {
+			t.Errorf("%s: expected ok=%v, got %v", tt.line, tt.ok, ok)
+			continue
+		}
+		if ok && (r.Method != tt.method || r.Pattern != tt.pattern || r.Handler != tt.handler) {
+			t.Errorf("%s: unexpected route %+v", tt.line, r)
+		}
+	}
+}
+
+func TestMatchRoutes(t *testing.T) {
+	// Use the web server's routes as the fixture
+	source, err := os.ReadFile("../../cmd/web/main.go")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	routes := []route{}
+	for i, line := range strings.Split(string(source), "\n") {
+		if r, ok := parseRouteLine(line); ok {
+			r.Path, r.Line = "cmd/web/main.go", i+1
+			routes = append(routes, r)
+		}
+	}
+
+	tests := []struct {
+		method  string
+		path    string
+		handler string
+	}{
+		{"GET", "/api/logs/3/source", "handleLogDetail"},
+		{"GET", "/api/logs", "handleLogs"},
+		{"POST", "/api/upload", "handleUpload"},
+		{"GET", "/index.html", "handleIndex"},
+	}
+	for _, tt := range tests {
+		matches := matchRoutes(routes, tt.method, tt.path)
+		if len(matches) != 1 || matches[0].Handler != tt.handler {
+			t.Errorf("%s %s: expected %s, got %+v", tt.method, tt.path, tt.handler, matches)
+		}
+	}
+
+	if matches := matchRoutes([]route{{Method: "POST", Pattern: "/users/:id"}}, "GET", "/users/1"); len(matches) != 0 {
+		t.Errorf("Expected routes for other methods to be skipped, got %+v", matches)
+	}
+}
@@ -0,0 +1,67 @@
+package log
+
+import (
+	"strings"
+
+	"vlsa/internal/analysis"
+)
+
+// Fills in the function, type and package each source is in.
+// Go files are parsed, other languages are read by their braces
+// or indentation. Fields that are already known are kept.
+func annotateSymbols(sources []SourceMapping) {
+	for i := range sources {
+		s := &sources[i]
+		annotateSymbols(s.Callers)
+		if s.Path == "" || s.SourceCode == "" || (s.Function != "" && s.Package != "") {
+			continue
+		}
+
+		if strings.HasSuffix(s.Path, ".go") {
+			f, err := analysis.ParseFile(s.Path, s.SourceCode)
+			if err != nil {
+				continue
+			}
+			if s.Package == "" {
+				s.Package = f.AST.Name.Name
+			}
+			if fn, ok := analysis.EnclosingFunc(s.Path, s.SourceCode, s.Line); ok {
+				if s.Function == "" {
+					s.Function = fn.String()
+				}
+				if s.Type == "" {
+					s.Type = strings.TrimPrefix(fn.Receiver, "*")
+				}
+			}
+			continue
+		}
+
+		if s.Package == "" {
+			s.Package = analysis.TextPackage(s.SourceCode)
+		}
+		if fn, ok := analysis.EnclosingTextFunc(s.Path, s.SourceCode, s.Line); ok {
+			if s.Function == "" {
+				s.Function = fn.Name
+			}
+			if s.Type == "" {
+				s.Type = fn.Type
+			}
+		}
+	}
+}
+
+// Returns a readable name for where the source is, like
+// lib.(*Server).handle for Go or com.acme.Audit.denied elsewhere.
+func (s SourceMapping) Symbol() string {
+	// Go functions are already named the way Go prints them
+	if strings.Contains(s.Function, ".") || s.Function == "" {
+		return s.Function
+	}
+	parts := []string{}
+	for _, p := range []string{s.Package, s.Type, s.Function} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,22 @@
+package log
+
+import "testing"
+
+func TestAnnotateSymbols(t *testing.T) {
+	sources := []SourceMapping{
+		{Path: "src/auth.go", Line: 6, SourceCode: "package main\n\nimport \"log\"\n\nfunc (s *Server) authenticateUser(email string) bool {\n\tlog.Printf(\"User login attempt for %s\", email)\n\treturn true\n}\n"},
+		{Path: "Audit.java", Line: 5, SourceCode: "package com.acme.identity;\n\npublic class Audit {\n    public void denied(String user) {\n        log.warn(\"Access denied\");\n    }\n}\n"},
+		{Path: "panic.go", Line: 1, Function: "main.(*T).f", SourceCode: "package main\n"},
+	}
+	annotateSymbols(sources)
+
+	expected := []string{"main.(*Server).authenticateUser", "com.acme.identity.Audit.denied", "main.(*T).f"}
+	for i, s := range sources {
+		if s.Symbol() != expected[i] {
+			t.Errorf("Expected %s, got %s (%+v)", expected[i], s.Symbol(), s)
+		}
+	}
+	if sources[0].Type != "Server" || sources[0].Package != "main" {
+		t.Errorf("Unexpected Go symbol %+v", sources[0])
+	}
+}
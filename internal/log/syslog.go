@@ -0,0 +1,258 @@
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterParser(rfc3164Parser{})
+	RegisterParser(rfc5424Parser{})
+}
+
+// Syslog severities indexed by the lower three bits of the priority.
+var syslogSeverities = []string{"emergency", "alert", "critical", "error", "warning", "notice", "info", "debug"}
+
+// Syslog facilities indexed by the priority divided by eight.
+var syslogFacilities = []string{"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron", "local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7"}
+
+// Parses BSD syslog lines (<34>Oct 11 22:14:15 host app[123]: message) as
+// written by rsyslog's default file template. The priority is optional
+// since rsyslog leaves it out of files.
+type rfc3164Parser struct{}
+
+func (rfc3164Parser) Name() string { return "rfc3164" }
+
+func (rfc3164Parser) Sniff(name string, head []string) float64 {
+	return sniffAll(head, 0.8, func(line string) bool {
+		_, ok := parseRFC3164(line, time.Now())
+		return ok
+	})
+}
+
+func (rfc3164Parser) Parse(file io.Reader) ([]Log, error) {
+	now := time.Now()
+	return parseLines(file, func(line string) (Log, bool) {
+		return parseRFC3164(line, now)
+	})
+}
+
+// Parses IETF syslog lines
+// (<165>1 2003-10-11T22:14:15.003Z host app 1234 ID47 [sd@1 k="v"] message).
+type rfc5424Parser struct{}
+
+func (rfc5424Parser) Name() string { return "rfc5424" }
+
+func (rfc5424Parser) Sniff(name string, head []string) float64 {
+	return sniffAll(head, 0.9, func(line string) bool {
+		_, ok := parseRFC5424(line)
+		return ok
+	})
+}
+
+func (rfc5424Parser) Parse(file io.Reader) ([]Log, error) {
+	return parseLines(file, parseRFC5424)
+}
+
+// Scores the head of a file by the fraction of lines the match function accepts.
+func sniffAll(head []string, weight float64, match func(string) bool) float64 {
+	if len(head) == 0 {
+		return 0
+	}
+	matches := 0
+	for _, line := range head {
+		if match(line) {
+			matches++
+		}
+	}
+	return weight * float64(matches) / float64(len(head))
+}
+
+// Parses a file one line at a time, lines that don't parse are
+// appended to the previous log since they are usually wrapped messages.
+func parseLines(file io.Reader, parse func(string) (Log, bool)) ([]Log, error) {
+	logs := []Log{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		l, ok := parse(line)
+		l.Raw = line
+		if !ok {
+			if len(logs) > 0 {
+				logs[len(logs)-1].Message += "\n" + line
+				logs[len(logs)-1].Raw += "\n" + line
+			}
+			continue
+		}
+		logs = append(logs, l)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading log file: %v", err)
+	}
+
+	return logs, nil
+}
+
+// Reads the <PRI> header and fills in the level and facility.
+// Returns the rest of the line.
+func parsePriority(line string, l *Log) (string, bool) {
+	if !strings.HasPrefix(line, "<") {
+		return line, false
+	}
+	end := strings.IndexByte(line, '>')
+	if end < 2 || end > 4 {
+		return line, false
+	}
+	// Only digits, Atoi would also take a sign
+	for _, c := range line[1:end] {
+		if c < '0' || c > '9' {
+			return line, false
+		}
+	}
+	pri, err := strconv.Atoi(line[1:end])
+	if err != nil || pri > 191 {
+		return line, false
+	}
+
+	l.Level = syslogSeverities[pri&7]
+	l.Attributes["facility"] = syslogFacilities[pri>>3]
+	return line[end+1:], true
+}
+
+func parseRFC3164(line string, now time.Time) (Log, bool) {
+	l := Log{Attributes: map[string]string{}, Sources: []SourceMapping{}}
+	rest, _ := parsePriority(line, &l)
+
+	// Timestamps look like "Jun  9 03:40:54" and don't include a year
+	if len(rest) < len(time.Stamp)+1 {
+		return Log{}, false
+	}
+	t, err := time.Parse(time.Stamp, rest[:len(time.Stamp)])
+	if err != nil {
+		return Log{}, false
+	}
+	t = t.AddDate(now.Year(), 0, 0)
+	// Logs from December read in January belong to last year
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	l.Time = t
+
+	fields := strings.SplitN(strings.TrimSpace(rest[len(time.Stamp):]), " ", 2)
+	if len(fields) < 2 {
+		return Log{}, false
+	}
+	l.Host = fields[0]
+	rest = fields[1]
+
+	// The tag is the app name followed by an optional [pid] and a colon
+	tag, msg, found := strings.Cut(rest, ": ")
+	if !found || strings.Contains(tag, " ") {
+		l.Message = rest
+		return l, true
+	}
+	if open := strings.IndexByte(tag, '['); open > 0 && strings.HasSuffix(tag, "]") {
+		l.Attributes["procid"] = tag[open+1 : len(tag)-1]
+		tag = tag[:open]
+	}
+	l.Service = tag
+	l.Message = msg
+	return l, true
+}
+
+func parseRFC5424(line string) (Log, bool) {
+	l := Log{Attributes: map[string]string{}, Sources: []SourceMapping{}}
+	rest, ok := parsePriority(line, &l)
+	if !ok || !strings.HasPrefix(rest, "1 ") {
+		return Log{}, false
+	}
+
+	// VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID
+	fields := strings.SplitN(rest, " ", 7)
+	if len(fields) < 7 {
+		return Log{}, false
+	}
+	if fields[1] != "-" {
+		t, err := time.Parse(time.RFC3339Nano, fields[1])
+		if err != nil {
+			return Log{}, false
+		}
+		l.Time = t
+	}
+	l.Host = nilValue(fields[2])
+	l.Service = nilValue(fields[3])
+	if procid := nilValue(fields[4]); procid != "" {
+		l.Attributes["procid"] = procid
+	}
+	if msgid := nilValue(fields[5]); msgid != "" {
+		l.Attributes["msgid"] = msgid
+	}
+
+	msg, ok := parseStructuredData(fields[6], l.Attributes)
+	if !ok {
+		return Log{}, false
+	}
+	// Messages may start with a UTF-8 byte order mark
+	l.Message = strings.TrimPrefix(msg, "\ufeff")
+	return l, true
+}
+
+// Reads RFC 5424 structured data ([id key="value"]...) into attributes
+// named id.key and returns the message that follows it.
+func parseStructuredData(s string, attrs map[string]string) (string, bool) {
+	if strings.HasPrefix(s, "-") {
+		return strings.TrimPrefix(strings.TrimPrefix(s, "-"), " "), true
+	}
+
+	i := 0
+	for i < len(s) && s[i] == '[' {
+		i++
+		start := i
+		for i < len(s) && s[i] != ' ' && s[i] != ']' {
+			i++
+		}
+		id := s[start:i]
+
+		for i < len(s) && s[i] != ']' {
+			i++ // Skip the space before the param
+			start = i
+			for i < len(s) && s[i] != '=' {
+				i++
+			}
+			key := s[start:i]
+			i += 2 // Skip ="
+			var value strings.Builder
+			for i < len(s) && s[i] != '"' {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteByte(s[i])
+				i++
+			}
+			i++ // Skip the closing quote
+			attrs[id+"."+key] = value.String()
+		}
+		if i >= len(s) {
+			return "", false
+		}
+		i++ // Skip ]
+	}
+	return strings.TrimPrefix(s[i:], " "), true
+}
+
+// Syslog uses a dash for fields that have no value.
+func nilValue(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,44 @@
+package log
+
+import (
+	"io"
+	"strings"
+)
+
+func init() {
+	RegisterParser(textParser{})
+}
+
+// Treats every line as a log message. Used as the fallback when no
+// other parser recognizes the file.
+type textParser struct{}
+
+func (textParser) Name() string { return "text" }
+
+func (textParser) Sniff(name string, head []string) float64 {
+	if len(head) == 0 {
+		return 0
+	}
+	return 0.05
+}
+
+func (textParser) Parse(file io.Reader) ([]Log, error) {
+	// Crashes end up in plain text logs so their stacks are kept too
+	return parseText(file)
+}
+
+// Reads a single line of a plain text log.
+func textLine(line string) Log {
+	l := Log{Message: strings.TrimSpace(line), Raw: line, Sources: []SourceMapping{}}
+	// Most plain text logs lead with a timestamp that is either
+	// one (2025-06-19T03:40:54Z) or two (2025-06-19 03:40:54) fields long
+	fields := strings.Fields(l.Message)
+	for n := min(len(fields)-1, 3); n > 0; n-- {
+		if t, ok := parseTime(strings.Join(fields[:n], " ")); ok {
+			l.Time = t
+			l.Message = strings.Join(fields[n:], " ")
+			break
+		}
+	}
+	return l
+}
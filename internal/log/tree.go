@@ -0,0 +1,494 @@
+package log
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"vlsa/internal/analysis"
+	"vlsa/internal/bus"
+)
+
+// A tree of source files that logs are mapped against. Source roots are
+// directories on disk, git revisions or release archives, and each is
+// searched and read in place, without a checkout or extraction.
+type sourceTree interface {
+	// Searches the tree with ripgrep's pattern arguments and returns
+	// path:line:text lines. Reports false when nothing matched.
+	search(patternArgs []string) (string, bool)
+	// Reads a file found by search or listed by files.
+	read(path string) (string, error)
+	// Lists every file in the tree.
+	files() []string
+}
+
+var (
+	trees   = map[string]sourceTree{}
+	treesMu sync.Mutex // Call paths are looked up in the background
+)
+
+// Returns the tree for a source root:
+//   - git:<repo>@<ref> for a branch, tag or commit of a repository
+//   - <commit>:<dir> for a directory at a revision, see SplitRevision
+//   - a .tar.gz, .tgz, .tar or .zip release archive
+//   - deps:<root> for the Go modules the root requires, see Dependencies
+//   - any other directory, searched with ripgrep
+func treeFor(root string) sourceTree {
+	treesMu.Lock()
+	t, ok := trees[root]
+	treesMu.Unlock()
+	if ok {
+		return t
+	}
+
+	t = dirTree(root)
+	if rev, dir := SplitRevision(root); rev != "" {
+		t = revisionTree{rev, dir}
+	} else if repo, ref, ok := gitSpec(root); ok {
+		rev := resolveRevision(repo, ref)
+		if rev == "" {
+			bus.LogChannel <- fmt.Sprintf("Error resolving %s: %s is not a commit in %s", root, ref, repo)
+		}
+		t = revisionTree{rev, repo}
+	} else if inner, ok := strings.CutPrefix(root, "deps:"); ok {
+		t = dependencyTree(Dependencies(inner))
+	} else if isArchive(root) {
+		t = &archiveTree{path: root}
+	}
+	treesMu.Lock()
+	defer treesMu.Unlock()
+	// Keep the tree another lookup stored first, archives are loaded once
+	if other, ok := trees[root]; ok {
+		return other
+	}
+	trees[root] = t
+	return t
+}
+
+// Splits a git:<repo>@<ref> root into the repository and the ref,
+// which is HEAD when it is left out.
+func gitSpec(root string) (repo string, ref string, ok bool) {
+	spec, ok := strings.CutPrefix(root, "git:")
+	if !ok {
+		return "", "", false
+	}
+	repo, ref = spec, "HEAD"
+	if i := strings.LastIndex(spec, "@"); i >= 0 {
+		repo, ref = spec[:i], spec[i+1:]
+	}
+	if repo == "" {
+		repo = "."
+	}
+	return repo, ref, true
+}
+
+// Returns the files of a source root for analysis, and the root its
+// paths start with.
+func analysisSource(root string) (string, analysis.Source) {
+	switch t := treeFor(root).(type) {
+	case revisionTree:
+		if t.dir == "" || t.dir == "." {
+			return t.rev + ":", treeSource{t}
+		}
+		return t.rev + ":" + filepath.Clean(t.dir), treeSource{t}
+	case *archiveTree:
+		return t.path, treeSource{t}
+	case dirTree:
+		return root, analysis.Dir(root)
+	default:
+		return root, treeSource{t}
+	}
+}
+
+// Lets analysis list and read the files of a tree.
+type treeSource struct{ tree sourceTree }
+
+func (s treeSource) Files() []string                  { return s.tree.files() }
+func (s treeSource) Read(path string) (string, error) { return s.tree.read(path) }
+
+// Returns the tree a path found by a search belongs to.
+func treeOf(path string) sourceTree {
+	if rev, dir := SplitRevision(path); rev != "" {
+		return revisionTree{rev, dir}
+	}
+	if archive, ok := archiveOf(path); ok {
+		return treeFor(archive)
+	}
+	return dirTree("")
+}
+
+// Extensions of the release archives that can be used as source roots.
+var archiveExts = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+
+func isArchive(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Files in archives are named <archive>/<path in the archive>.
+// Returns the archive a path is in, if it is in one.
+func archiveOf(path string) (string, bool) {
+	lower := strings.ToLower(filepath.ToSlash(path))
+	for _, ext := range archiveExts {
+		if i := strings.Index(lower, ext+"/"); i >= 0 {
+			return path[:i+len(ext)], true
+		}
+	}
+	return "", false
+}
+
+// A directory on disk, searched with ripgrep.
+type dirTree string
+
+func (d dirTree) search(patternArgs []string) (string, bool) {
+	if d == "" {
+		return ripgrep(patternArgs)
+	}
+	return ripgrep(patternArgs, string(d))
+}
+
+// Runs ripgrep with the pattern arguments over the paths, or the
+// working directory when there are none.
+func ripgrep(patternArgs []string, paths ...string) (string, bool) {
+	args := append([]string{"--line-number"}, patternArgs...)
+	args = append(args, "--glob", "!**/*.csv")
+	args = append(args, paths...)
+	cmd := exec.Command("rg", args...)
+
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(err.Error(), "exit status 1") {
+			return "", false
+		} else {
+			fmt.Fprintf(os.Stderr, "Error running command: %v\n%s\n\n\n%s", err, string(out), "Do you have ripgrep installed? It is required for source mapping.")
+			os.Exit(1)
+		}
+	}
+
+	return string(out), true
+}
+
+func (d dirTree) read(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	source := ""
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		source += scanner.Text() + "\n"
+	}
+	return source, scanner.Err()
+}
+
+func (d dirTree) files() []string {
+	root := string(d)
+	if root == "" {
+		root = "."
+	}
+	files := []string{}
+	filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, p)
+		return nil
+	})
+	return files
+}
+
+// The source of the modules a root depends on. Modules on disk are
+// searched in one ripgrep run, vendored ones of a git revision or an
+// archive within the tree they are in.
+type dependencyTree []Dependency
+
+func (d dependencyTree) search(patternArgs []string) (string, bool) {
+	dirs, out := []string{}, []string{}
+	for _, dep := range d {
+		t := treeOf(dep.Dir)
+		if _, ok := t.(dirTree); ok {
+			dirs = append(dirs, dep.Dir)
+			continue
+		}
+		found, _ := t.search(patternArgs)
+		for _, line := range strings.Split(found, "\n") {
+			rev, rest := SplitRevision(line)
+			if path, _, ok := strings.Cut(rest, ":"); ok && inDependency(revisionPath(rev, path), dep.Dir) {
+				out = append(out, line)
+			}
+		}
+	}
+	if len(dirs) > 0 {
+		// Libraries' tests log plenty that is never seen in production
+		args := append([]string{"--glob", "!**/*_test.go", "--glob", "!**/testdata/**"}, patternArgs...)
+		if found, ok := ripgrep(args, dirs...); ok {
+			out = append(out, strings.TrimSuffix(found, "\n"))
+		}
+	}
+	return strings.Join(out, "\n"), len(out) > 0
+}
+
+// Reports whether the file is in the module's directory and isn't one
+// of its tests.
+func inDependency(path string, dir string) bool {
+	path = filepath.ToSlash(path)
+	rel, ok := strings.CutPrefix(path, filepath.ToSlash(dir)+"/")
+	return ok && !strings.HasSuffix(rel, "_test.go") && !strings.Contains("/"+rel, "/testdata/")
+}
+
+func (d dependencyTree) read(path string) (string, error) {
+	return treeOf(path).read(path)
+}
+
+func (d dependencyTree) files() []string {
+	files := []string{}
+	for _, dep := range d {
+		files = append(files, dependencyFiles(dep.Dir)...)
+	}
+	return files
+}
+
+// Lists the files in a module's directory, in whichever tree it is in.
+func dependencyFiles(dir string) []string {
+	t := treeOf(dir)
+	if _, ok := t.(dirTree); ok {
+		return dirTree(dir).files()
+	}
+	files := []string{}
+	for _, f := range t.files() {
+		if strings.HasPrefix(filepath.ToSlash(f), filepath.ToSlash(dir)+"/") {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// A directory as it was at a git revision, searched with git grep.
+type revisionTree struct {
+	rev string
+	dir string
+}
+
+func (r revisionTree) search(patternArgs []string) (string, bool) {
+	if r.rev == "" {
+		return "", false
+	}
+	return gitGrep(patternArgs, r.rev, r.dir)
+}
+
+func (r revisionTree) read(path string) (string, error) {
+	rev, file := SplitRevision(path)
+	if rev == "" {
+		return "", fmt.Errorf("%s is not at a revision", path)
+	}
+	return gitShow(rev, file)
+}
+
+func (r revisionTree) files() []string {
+	if r.rev == "" {
+		return nil
+	}
+	return gitListFiles(r.rev, r.dir)
+}
+
+// Files larger than this are left out of archives, they are rarely source.
+const maxArchiveFile = 4 << 20
+
+// A release archive, read into memory the first time it is used.
+type archiveTree struct {
+	path     string
+	once     sync.Once
+	contents map[string]string // Keyed by <archive>/<path in the archive>
+	names    []string
+}
+
+func (a *archiveTree) load() {
+	a.once.Do(func() {
+		a.contents = map[string]string{}
+		add := func(name string, r io.Reader, size int64) {
+			if size > maxArchiveFile || skippedPath(name) {
+				return
+			}
+			data, err := io.ReadAll(io.LimitReader(r, maxArchiveFile))
+			// Binary files can't be searched for log messages
+			if err != nil || bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+				return
+			}
+			path := a.path + "/" + strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "/")
+			a.contents[path] = string(data)
+			a.names = append(a.names, path)
+		}
+
+		var err error
+		if strings.HasSuffix(strings.ToLower(a.path), ".zip") {
+			err = readZip(a.path, add)
+		} else {
+			err = readTar(a.path, add)
+		}
+		if err != nil {
+			bus.LogChannel <- fmt.Sprintf("Error reading source archive %s: %v", a.path, err)
+		}
+		sort.Strings(a.names)
+	})
+}
+
+// Reports whether the archived file is in a directory that is never searched.
+func skippedPath(name string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(name), "/") {
+		if skipDirs[part] {
+			return true
+		}
+	}
+	return false
+}
+
+func readZip(path string, add func(string, io.Reader, int64)) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		add(f.Name, rc, int64(f.UncompressedSize64))
+		rc.Close()
+	}
+	return nil
+}
+
+func readTar(path string, add func(string, io.Reader, int64)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if !strings.HasSuffix(strings.ToLower(path), ".tar") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag == tar.TypeReg {
+			add(h.Name, tr, h.Size)
+		}
+	}
+}
+
+func (a *archiveTree) search(patternArgs []string) (string, bool) {
+	a.load()
+	re, ok := searchPattern(patternArgs)
+	if !ok {
+		return "", false
+	}
+
+	var out strings.Builder
+	for _, name := range a.names {
+		if strings.HasSuffix(strings.ToLower(name), ".csv") {
+			continue
+		}
+		for i, line := range strings.Split(a.contents[name], "\n") {
+			if re.MatchString(line) {
+				fmt.Fprintf(&out, "%s:%d:%s\n", name, i+1, line)
+			}
+		}
+	}
+	return out.String(), out.Len() > 0
+}
+
+func (a *archiveTree) read(path string) (string, error) {
+	a.load()
+	source, ok := a.contents[filepath.ToSlash(path)]
+	if !ok {
+		return "", fmt.Errorf("%s is not in %s", path, a.path)
+	}
+	if !strings.HasSuffix(source, "\n") {
+		source += "\n"
+	}
+	return source, nil
+}
+
+func (a *archiveTree) files() []string {
+	a.load()
+	return a.names
+}
+
+// Compiles ripgrep's pattern arguments (-F, -w and -e or a single
+// pattern) into one expression. Rust's regular expressions, which
+// ripgrep uses, mostly share Go's syntax.
+func searchPattern(patternArgs []string) (*regexp.Regexp, bool) {
+	fixed, word := false, false
+	patterns := []string{}
+	for i := 0; i < len(patternArgs); i++ {
+		switch a := patternArgs[i]; {
+		case a == "-F":
+			fixed = true
+		case a == "-w":
+			word = true
+		case a == "-e" && i+1 < len(patternArgs):
+			patterns = append(patterns, patternArgs[i+1])
+			i++
+		case !strings.HasPrefix(a, "-"):
+			patterns = append(patterns, a)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, false
+	}
+	if fixed {
+		for i, p := range patterns {
+			patterns[i] = regexp.QuoteMeta(p)
+		}
+	}
+	expr := "(?:" + strings.Join(patterns, ")|(?:") + ")"
+	if word {
+		expr = `\b(?:` + expr + `)\b`
+	}
+	re, err := regexp.Compile(expr)
+	return re, err == nil
+}
@@ -0,0 +1,133 @@
+package log
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"vlsa/internal/analysis"
+)
+
+var archivedFiles = map[string]string{
+	"app-1.2/pay/pay.go":                "package pay\n\nfunc charge() {\n\tlog.Error(\"Payment failed for order\")\n}\n",
+	"app-1.2/node_modules/dep/index.js": "console.error(\"Payment failed for order\")\n",
+	"app-1.2/export.csv":                "Payment failed for order\n",
+}
+
+func writeTarGz(t *testing.T, path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, src := range archivedFiles {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(src)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(src))
+	}
+	tw.Close()
+	gz.Close()
+}
+
+func writeZip(t *testing.T, path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, src := range archivedFiles {
+		w, _ := zw.Create(name)
+		w.Write([]byte(src))
+	}
+	zw.Close()
+}
+
+func TestArchiveTree(t *testing.T) {
+	dir := t.TempDir()
+	for _, c := range []struct {
+		name  string
+		write func(*testing.T, string)
+	}{
+		{"app-1.2.tar.gz", writeTarGz},
+		{"app-1.2.zip", writeZip},
+	} {
+		archive := filepath.Join(dir, c.name)
+		c.write(t, archive)
+
+		// Dependencies and exports aren't searched
+		sources := rg("Payment failed for order", archive)
+		if len(sources) != 1 || sources[0].Path != archive+"/app-1.2/pay/pay.go" || sources[0].Line != 4 {
+			t.Fatalf("Unexpected sources in %s: %+v", c.name, sources)
+		}
+		if !strings.Contains(sources[0].SourceCode, "func charge()") {
+			t.Errorf("Expected the source code of %s, got %q", sources[0].Path, sources[0].SourceCode)
+		}
+		if !fileExists(sources[0].Path) || fileExists(archive+"/app-1.2/missing.go") {
+			t.Errorf("Expected only archived files to exist in %s", c.name)
+		}
+		if files := listFiles(archive); len(files) != 2 {
+			t.Errorf("Expected the 2 searchable files of %s, got %v", c.name, files)
+		}
+	}
+}
+
+func TestSearchPattern(t *testing.T) {
+	for _, c := range []struct {
+		args  []string
+		line  string
+		match bool
+	}{
+		{[]string{"-F", "user (id"}, `log("user (id 3)")`, true},
+		{[]string{"-F", "-w", "-e", "charge", "-e", "refund"}, "func refund() {", true},
+		{[]string{"-F", "-w", "-e", "charge"}, "func charged() {", false},
+		{[]string{`Failed to .* user`}, "Failed to load user", true},
+	} {
+		re, ok := searchPattern(c.args)
+		if !ok || re.MatchString(c.line) != c.match {
+			t.Errorf("Expected %v matching %q to be %v", c.args, c.line, c.match)
+		}
+	}
+}
+
+func TestGitSpecTree(t *testing.T) {
+	root, commit := testGitRepo(t)
+	old := commit("pay.go", "package pay\n\nfunc charge() {\n\tlog.Error(\"Payment failed for order\")\n}\n")
+	commit("pay.go", "package pay\n\nfunc charge() {\n\tlog.Error(\"Charge declined\")\n}\n")
+	// Uncommitted changes aren't searched
+	os.WriteFile(filepath.Join(root, "pay.go"), []byte("package pay\n"), 0644)
+
+	if sources := rg("Charge declined", "git:"+root); len(sources) != 1 || sources[0].Line != 4 {
+		t.Fatalf("Unexpected sources at HEAD %+v", sources)
+	}
+	sources := rg("Payment failed for order", "git:"+root+"@"+old[:8])
+	if len(sources) != 1 || !strings.Contains(sources[0].SourceCode, "Payment failed") {
+		t.Fatalf("Unexpected sources at %s %+v", old[:8], sources)
+	}
+	if rev, file := SplitRevision(sources[0].Path); rev != old || file != filepath.Join(root, "pay.go") {
+		t.Errorf("Expected pay.go at %s, got %s at %s", old, file, rev)
+	}
+}
+
+func TestAnalysisSource(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "app-1.2.tar.gz")
+	writeTarGz(t, archive)
+	statements := analysis.LogStatements(analysisSource(archive))
+	if len(statements) != 1 || statements[0].Path != archive+"/app-1.2/pay/pay.go" || statements[0].Line != 4 {
+		t.Errorf("Unexpected log statements in the archive %+v", statements)
+	}
+
+	root, commit := testGitRepo(t)
+	rev := commit("pay.go", "package pay\n\nfunc charge() {\n\tlog.Error(\"Payment failed for order\")\n}\n")
+	// Only the committed statement is at the revision
+	os.WriteFile(filepath.Join(root, "pay.go"), []byte("package pay\n"), 0644)
+	statements = analysis.LogStatements(analysisSource("git:" + root))
+	if len(statements) != 1 || statements[0].Path != revisionPath(rev, filepath.Join(root, "pay.go")) {
+		t.Errorf("Unexpected log statements at %s %+v", rev[:8], statements)
+	}
+}
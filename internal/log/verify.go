@@ -0,0 +1,139 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// A message template and where its logs were mapped to when the contract
+// was recorded. Alerts and dashboards depend on the template staying put.
+type ContractEntry struct {
+	Service  string           `json:"service,omitempty"`
+	Template string           `json:"template"`
+	Sources  []ContractSource `json:"sources"`
+}
+
+type ContractSource struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Function string `json:"function,omitempty"`
+}
+
+// How a template's mapping differs from the recorded contract.
+type ContractChange struct {
+	ContractEntry
+	Kind   string // disappeared, moved, ambiguous or new
+	Detail string
+}
+
+// Reports whether the change breaks the contract. New templates don't.
+func (c ContractChange) Regressed() bool {
+	return c.Kind != "new"
+}
+
+// Returns every distinct template in the logs with its current mapping.
+// Templates are the text searched for when mapping, so logs that only
+// differ in their dynamic parts share one. Fully dynamic logs are left out.
+func Contract(logs []Log) []ContractEntry {
+	entries := []ContractEntry{}
+	seen := map[string]bool{}
+	for _, l := range logs {
+		template := parseOutDynamics(l.Message, true)
+		key := l.Service + "\x00" + template
+		if template == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		e := ContractEntry{Service: l.Service, Template: template, Sources: []ContractSource{}}
+		for _, s := range l.Sources {
+			if s.Path != "" {
+				e.Sources = append(e.Sources, ContractSource{Path: s.Path, Line: s.Line, Function: s.Symbol()})
+			}
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Service != entries[j].Service {
+			return entries[i].Service < entries[j].Service
+		}
+		return entries[i].Template < entries[j].Template
+	})
+	return entries
+}
+
+// Compares the current mapping of each template against the recorded
+// one. Line numbers are expected to shift and aren't compared, only
+// whether the template is still found, in the same functions, once.
+func VerifyContract(recorded, current []ContractEntry) []ContractChange {
+	before := map[string]ContractEntry{}
+	for _, e := range recorded {
+		before[e.Service+"\x00"+e.Template] = e
+	}
+
+	changes := []ContractChange{}
+	for _, e := range current {
+		old, ok := before[e.Service+"\x00"+e.Template]
+		switch {
+		case !ok:
+			changes = append(changes, ContractChange{e, "new", "Not in the contract"})
+		case len(old.Sources) == 0:
+			// Templates that never mapped have nothing to break
+		case len(e.Sources) == 0:
+			changes = append(changes, ContractChange{e, "disappeared", "Was logged by " + describeSources(old.Sources)})
+		case !slices.ContainsFunc(e.Sources, func(s ContractSource) bool { return containsPlace(old.Sources, s) }):
+			changes = append(changes, ContractChange{e, "moved", fmt.Sprintf("Moved from %s to %s", describeSources(old.Sources), describeSources(e.Sources))})
+		case len(old.Sources) == 1 && len(e.Sources) > 1:
+			changes = append(changes, ContractChange{e, "ambiguous", "Now matches " + describeSources(e.Sources)})
+		}
+	}
+	return changes
+}
+
+// Returns the function, or the file outside any function, the source is in.
+func (s ContractSource) place() string {
+	if s.Function != "" {
+		return s.Function
+	}
+	return s.Path
+}
+
+func containsPlace(sources []ContractSource, s ContractSource) bool {
+	return slices.ContainsFunc(sources, func(o ContractSource) bool { return o.place() == s.place() })
+}
+
+func describeSources(sources []ContractSource) string {
+	places := []string{}
+	for _, s := range sources {
+		places = append(places, fmt.Sprintf("%s (%s:%d)", s.place(), s.Path, s.Line))
+	}
+	return strings.Join(places, ", ")
+}
+
+// Reads a contract written by WriteContract.
+func ReadContract(path string) ([]ContractEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading contract: %v", err)
+	}
+	entries := []ContractEntry{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing contract %s: %v", path, err)
+	}
+	return entries, nil
+}
+
+func WriteContract(path string, entries []ContractEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding contract: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing contract: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package log
+
+import "testing"
+
+func TestVerifyContract(t *testing.T) {
+	at := func(function string, lines ...int) []SourceMapping {
+		sources := []SourceMapping{}
+		for _, line := range lines {
+			sources = append(sources, SourceMapping{Path: "svc/user.go", Line: line, Function: function})
+		}
+		return sources
+	}
+	recorded := Contract([]Log{
+		{Service: "api", Message: "User created: 42", Sources: at("svc.create", 10)},
+		{Service: "api", Message: "User created: 43", Sources: at("svc.create", 10)},
+		{Service: "api", Message: "User deleted", Sources: at("svc.remove", 20)},
+		{Service: "api", Message: "User renamed", Sources: at("svc.rename", 30)},
+		{Service: "api", Message: "User saved", Sources: at("svc.save", 40)},
+		{Service: "api", Message: "Cache warmed", Sources: []SourceMapping{{DisplayMessage: "No source mapping found for this log message..."}}},
+	})
+	if len(recorded) != 5 || len(recorded[0].Sources) != 0 {
+		t.Fatalf("Expected one entry per template, got %+v", recorded)
+	}
+
+	current := Contract([]Log{
+		// Only the line moved
+		{Service: "api", Message: "User created: 44", Sources: at("svc.create", 12)},
+		{Service: "api", Message: "User deleted", Sources: []SourceMapping{{DisplayMessage: "No source mapping found for this log message..."}}},
+		{Service: "api", Message: "User renamed", Sources: at("svc.update", 35)},
+		{Service: "api", Message: "User saved", Sources: at("svc.save", 40, 80)},
+		{Service: "api", Message: "Cache warmed", Sources: []SourceMapping{{DisplayMessage: "No source mapping found for this log message..."}}},
+		{Service: "worker", Message: "Job started", Sources: at("svc.run", 5)},
+	})
+
+	kinds := map[string]string{}
+	for _, c := range VerifyContract(recorded, current) {
+		kinds[c.Service+" "+c.Template] = c.Kind
+	}
+	expected := map[string]string{
+		"api User deleted":   "disappeared",
+		"api User renamed":   "moved",
+		"api User saved":     "ambiguous",
+		"worker Job started": "new",
+	}
+	if len(kinds) != len(expected) {
+		t.Errorf("Expected %d changes, got %v", len(expected), kinds)
+	}
+	for template, kind := range expected {
+		if kinds[template] != kind {
+			t.Errorf("Expected %q to be %s, got %q", template, kind, kinds[template])
+		}
+	}
+}
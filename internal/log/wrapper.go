@@ -0,0 +1,108 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"vlsa/internal/analysis"
+)
+
+// Declarations of a function, left out when searching for its callers.
+var funcDefinition = regexp.MustCompile(`\b(?:def|fn|func|function|fun)\s+\w*\s*$|\bfunction\s*$`)
+
+// Finds the callers of sources inside logging wrappers, helpers like
+// LogAuthFailure whose body is only the logging call. The callers are
+// where the log really came from so they are listed under the wrapper.
+func resolveWrappers(sources []SourceMapping, root string) []SourceMapping {
+	sources = append([]SourceMapping{}, sources...)
+	for i, s := range sources {
+		if s.Path == "" || s.SourceCode == "" {
+			continue
+		}
+
+		var name string
+		var callers []SourceMapping
+		if strings.HasSuffix(s.Path, ".go") {
+			fn, ok := analysis.EnclosingFunc(s.Path, s.SourceCode, s.Line)
+			if !ok || !fn.IsWrapper() {
+				continue
+			}
+			name = fn.String()
+			callers = goCallers(fn, root)
+		} else {
+			fn, ok := analysis.EnclosingTextFunc(s.Path, s.SourceCode, s.Line)
+			if !ok || !fn.IsWrapper() {
+				continue
+			}
+			name = fn.Name
+			callers = textCallers(fn.Name, s.Path, root)
+		}
+
+		if len(callers) > 0 {
+			sources[i].Callers = callers
+			places := "places"
+			if len(callers) == 1 {
+				places = "place"
+			}
+			sources[i].DisplayMessage = fmt.Sprintf("Logged by the wrapper %s, called from %d %s", name, len(callers), places)
+		}
+	}
+	return sources
+}
+
+func goCallers(fn analysis.Func, root string) []SourceMapping {
+	key := cacheKey(root, "callers:"+fn.ID())
+	if callers, found := searchCache[key]; found {
+		return callers
+	}
+
+	callers := []SourceMapping{}
+	for _, c := range analysis.Load(analysisSource(root)).Callers(fn) {
+		source, err := readSource(c.Path)
+		if err != nil {
+			continue
+		}
+		callers = append(callers, SourceMapping{
+			Path:           c.Path,
+			Line:           c.Line,
+			Function:       c.Caller.String(),
+			DisplayMessage: "Calls the logging wrapper " + fn.String(),
+			SourceCode:     source,
+		})
+	}
+	searchCache[key] = callers
+	return callers
+}
+
+// Searches other languages for calls by name, skipping the definition.
+func textCallers(name string, definedIn string, root string) []SourceMapping {
+	key := cacheKey(root, "callers:"+definedIn+":"+name)
+	if callers, found := searchCache[key]; found {
+		return callers
+	}
+
+	callers := []SourceMapping{}
+	if out, found := rgSearch([]string{"-w", "-F", name}, root); found {
+		ext := filepath.Ext(definedIn)
+		for _, r := range parseRGOutput(out) {
+			if filepath.Ext(r.Path) != ext || isTestFile(r.Path) {
+				continue
+			}
+			lines := strings.Split(r.SourceCode, "\n")
+			if r.Line > len(lines) {
+				continue
+			}
+			line := lines[r.Line-1]
+			before, _, _ := strings.Cut(line, name)
+			if isCommentLine(line) || funcDefinition.MatchString(before) {
+				continue
+			}
+			r.DisplayMessage = "Calls the logging wrapper " + name
+			callers = append(callers, r)
+		}
+	}
+	searchCache[key] = callers
+	return callers
+}
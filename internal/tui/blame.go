@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"vlsa/internal/git"
+	"vlsa/internal/log"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var blameStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("180"))
+
+// Commits of the line's history shown in the blame panel.
+const blameHistory = 5
+
+// Who last changed a source's line, once it has been looked up.
+type blameMsg struct {
+	key   string
+	blame git.Blame
+	err   error
+}
+
+// Looks up who last changed the selected source's line when the blame
+// panel is open and the selection changed. Running git on every redraw
+// would make scrolling crawl, so it runs in the background.
+func (m *Model) fetchBlame() tea.Cmd {
+	source, ok := m.currentSource()
+	key := ""
+	if m.showBlame && ok && source.Path != "" {
+		key = fmt.Sprintf("%s:%d", source.Path, source.Line)
+	}
+	if key == m.blameKey {
+		return nil
+	}
+	m.blameKey, m.blame = key, nil
+	if key == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		rev, path := log.SplitRevision(source.Path)
+		b, err := git.BlameLineAt(rev, path, source.Line)
+		return blameMsg{key, b, err}
+	}
+}
+
+// Renders who last changed the source's line and the commits that
+// changed it before, shown above the source with 'b'.
+func renderBlame(blame *blameMsg, width int) string {
+	if blame == nil {
+		return subtleStyle.Render("Blame: loading…")
+	}
+	b, err := blame.blame, blame.err
+	if err != nil {
+		return subtleStyle.Render(truncate("Blame unavailable: "+err.Error(), width))
+	}
+
+	commit := func(c git.Commit) string {
+		if c.Uncommitted() {
+			return "Not committed yet"
+		}
+		return fmt.Sprintf("%s %s %s  %s", c.Short(), c.Date.Format("2006-01-02"), c.Author, c.Summary)
+	}
+	lines := []string{blameStyle.Render(truncate("Blame: "+commit(b.Commit), width))}
+	if !b.Commit.Uncommitted() && b.Commit.Email != "" {
+		lines = append(lines, subtleStyle.Render(truncate("       "+b.Commit.Email, width)))
+	}
+	if len(b.History) > 1 {
+		lines = append(lines, subtleStyle.Render("History:"))
+		for _, c := range b.History[:min(len(b.History), blameHistory)] {
+			lines = append(lines, subtleStyle.Render(truncate("  "+commit(c), width)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Shortens the text to fit the width in cells, without splitting
+// characters like the ones in an author's name.
+func truncate(s string, width int) string {
+	if width <= 1 || lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes)) > width-1 {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"slices"
+
+	"vlsa/internal/log"
+)
+
+// Returns how many of the callers of the wrapper at i are
+// listed right after it.
+func expandedCallers(sources []log.SourceMapping, i int) int {
+	callers := sources[i].Callers
+	if len(callers) == 0 || i+len(callers) >= len(sources) {
+		return 0
+	}
+	for j, c := range callers {
+		s := sources[i+1+j]
+		if s.Path != c.Path || s.Line != c.Line {
+			return 0
+		}
+	}
+	return len(callers)
+}
+
+// Reports whether the source at i is a caller listed under its wrapper.
+func isExpandedCaller(sources []log.SourceMapping, i int) bool {
+	for w := i - 1; w >= 0; w-- {
+		if n := expandedCallers(sources, w); n > 0 {
+			return i-w <= n
+		}
+	}
+	return false
+}
+
+// Shows or hides the callers of the logging wrapper at i,
+// listing them right after it in the log's sources.
+func toggleCallers(l *log.Log, i int) {
+	if i < 0 || i >= len(l.Sources) || len(l.Sources[i].Callers) == 0 {
+		return
+	}
+	if n := expandedCallers(l.Sources, i); n > 0 {
+		l.Sources = slices.Delete(l.Sources, i+1, i+1+n)
+		if l.SelectedSourceIdx > i {
+			l.SelectedSourceIdx = i
+		}
+		return
+	}
+	l.Sources = slices.Insert(l.Sources, i+1, l.Sources[i].Callers...)
+}
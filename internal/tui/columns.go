@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+
+	"vlsa/internal/log"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// Put in front of the messages of logs from recently changed code.
+const changedMarker = "Δ "
+
+// An optional column of the log table. Columns are only shown
+// when at least one of the loaded logs has a value for them.
+type logColumn struct {
+	title string
+	width int
+	value func(log.Log) string
+}
+
+var optionalColumns = []logColumn{
+	{"Level", 6, func(l log.Log) string { return l.Level }},
+	{"Service", 12, func(l log.Log) string { return l.Service }},
+	{"Host", 14, func(l log.Log) string { return l.Host }},
+	{"Trace", 16, func(l log.Log) string { return l.TraceID }},
+}
+
+// Returns the optional columns that have a value in any of the logs.
+func presentColumns(logs []log.Log) []logColumn {
+	columns := []logColumn{}
+	for _, c := range optionalColumns {
+		for _, l := range logs {
+			if c.value(l) != "" {
+				columns = append(columns, c)
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// Returns the optional columns currently shown in the table.
+func (m Model) shownColumns() []logColumn {
+	if m.hideColumns {
+		return nil
+	}
+	return m.columns
+}
+
+// Lays out the table columns for the width of the logs pane,
+// the log message gets whatever space the other columns leave.
+func (m Model) tableColumns(width int) []table.Column {
+	columns := []table.Column{{Title: "Timestamp", Width: 20}}
+	used := 22
+	for _, c := range m.shownColumns() {
+		columns = append(columns, table.Column{Title: c.title, Width: c.width})
+		used += c.width + 2
+	}
+	return append(columns, table.Column{Title: "Log", Width: max(width-used, 10)})
+}
+
+func (m Model) logRow(l log.Log) table.Row {
+	row := table.Row{fmt.Sprintf("%v", l.Time)}
+	for _, c := range m.shownColumns() {
+		row = append(row, c.value(l))
+	}
+	// Logs from code changed since --since stand out
+	if l.Changed() {
+		return append(row, changedMarker+l.Message)
+	}
+	return append(row, l.Message)
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"vlsa/internal/log"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	hitStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("78"))
+	missStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+)
+
+// Width of the bars showing how much of a file was logged.
+const coverageBarWidth = 20
+
+// Opens the coverage report of the loaded logs in place of the panes.
+func (m *Model) showCoverageReport() {
+	m.showCoverage = true
+	m.coverageView = viewport.New(m.x-2, m.y-3)
+	m.coverageView.SetContent(renderCoverage(log.Coverage(m.logs), m.x-2))
+}
+
+func renderCoverage(r log.CoverageReport, width int) string {
+	var b strings.Builder
+	nameWidth := max(width-coverageBarWidth-20, 20)
+	row := func(name string, hit, total int, pct float64) {
+		if len(name) > nameWidth {
+			name = "…" + name[len(name)-nameWidth+1:]
+		}
+		filled := int(pct / 100 * coverageBarWidth)
+		bar := hitStyle.Render(strings.Repeat("█", filled)) + subtleStyle.Render(strings.Repeat("░", coverageBarWidth-filled))
+		fmt.Fprintf(&b, "%-*s %s %4d/%-4d %5.1f%%\n", nameWidth, name, bar, hit, total, pct)
+	}
+
+	b.WriteString(keywordStyle.Render(fmt.Sprintf("Log coverage: %d of %d log statements seen (%.1f%%)", r.Hit, r.Statements, r.Percent())) + "\n")
+	b.WriteString(subtleStyle.Render("Press v or esc to go back to the logs") + "\n\n")
+
+	b.WriteString(subtleStyle.Render("Packages") + "\n")
+	for _, p := range r.Packages {
+		row(p.Package, p.Hit, p.Statements, p.Percent())
+	}
+	b.WriteString("\n" + subtleStyle.Render("Files") + "\n")
+	for _, f := range r.Files {
+		row(f.Path, f.Hit, len(f.Statements), f.Percent())
+	}
+
+	b.WriteString("\n" + subtleStyle.Render("Never logged") + "\n")
+	for _, f := range r.Files {
+		for _, s := range f.Statements {
+			if s.Hits > 0 {
+				continue
+			}
+			msg := s.Message
+			if msg == "" {
+				msg = "(dynamic message)"
+			}
+			fmt.Fprintf(&b, "%s %s %s\n", missStyle.Render(fmt.Sprintf("%s:%d", s.Path, s.Line)), subtleStyle.Render(s.Level), msg)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"strings"
+
+	"vlsa/internal/log"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// A single term of the log filter. Terms like host:web-1 match a field
+// of the log, terms without a field match the message.
+type filterTerm struct {
+	field string
+	value string
+}
+
+// Splits a filter like "host:web-1 app:api timeout" into its terms.
+// Every term has to match for a log to be shown.
+func parseFilter(s string) []filterTerm {
+	terms := []filterTerm{}
+	for _, f := range strings.Fields(strings.ToLower(s)) {
+		field, value, found := strings.Cut(f, ":")
+		if !found || value == "" {
+			terms = append(terms, filterTerm{value: f})
+			continue
+		}
+		terms = append(terms, filterTerm{field: field, value: value})
+	}
+	return terms
+}
+
+func (t filterTerm) matches(l log.Log) bool {
+	contains := func(s string) bool {
+		return strings.Contains(strings.ToLower(s), t.value)
+	}
+
+	switch t.field {
+	case "host":
+		return contains(l.Host)
+	case "service", "app":
+		return contains(l.Service)
+	case "level":
+		return contains(l.Level)
+	case "func", "function":
+		// Any of the places the log may come from
+		for _, s := range l.Sources {
+			if contains(s.Symbol()) {
+				return true
+			}
+		}
+		return false
+	case "is":
+		// is:changed shows the logs from code changed since --since
+		return t.value == "changed" && l.Changed()
+	case "":
+		return contains(l.Message)
+	default:
+		// Unknown fields fall back to the log's attributes
+		return contains(l.Attributes[t.field])
+	}
+}
+
+func matchesFilter(l log.Log, terms []filterTerm) bool {
+	for _, t := range terms {
+		if !t.matches(l) {
+			return false
+		}
+	}
+	return true
+}
+
+func newFilterInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "host:web-1 app:api func:handleLogin message text"
+	return ti
+}
+
+// Rebuilds the table rows from the logs that match the current filter,
+// keeping the cursor on the same log when it is still visible.
+func (m *Model) applyFilter() {
+	selected := m.cursorLog()
+	terms := parseFilter(m.filterInput.Value())
+
+	m.visible = m.visible[:0]
+	rows := []table.Row{}
+	cursor := 0
+	for i, l := range m.logs {
+		if !matchesFilter(l, terms) {
+			continue
+		}
+		if i <= selected {
+			cursor = len(m.visible)
+		}
+		m.visible = append(m.visible, i)
+		rows = append(rows, m.logRow(l))
+	}
+
+	// Columns have to match the rows before they are set
+	m.logTable.SetColumns(m.tableColumns(m.getLogsViewWidth()))
+	m.logTable.SetRows(rows)
+	m.logTable.SetCursor(cursor)
+	m.updateSourceSelector()
+}
+
+// Returns the index into logs of the log under the table cursor,
+// or -1 when no log is selected.
+func (m Model) cursorLog() int {
+	c := m.logTable.Cursor()
+	if c < 0 || c >= len(m.visible) || m.visible[c] >= len(m.logs) {
+		return -1
+	}
+	return m.visible[c]
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"vlsa/internal/analysis"
+	"vlsa/internal/log"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var breadcrumbStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("111"))
+
+// Returns the selected sources of the log at the cursor and the log
+// above it, in the order they were written. Exports are often sorted
+// newest first so the order of the rows can't be relied on.
+func (m Model) adjacentSources() (from, to log.SourceMapping, sameRequest bool, ok bool) {
+	c := m.logTable.Cursor()
+	if c < 1 || c >= len(m.visible) {
+		return from, to, false, false
+	}
+	current, previous := m.logs[m.visible[c]], m.logs[m.visible[c-1]]
+	if len(current.Sources) == 0 || len(previous.Sources) == 0 {
+		return from, to, false, false
+	}
+
+	to = current.Sources[min(current.SelectedSourceIdx, len(current.Sources)-1)]
+	from = previous.Sources[min(previous.SelectedSourceIdx, len(previous.Sources)-1)]
+	if previous.Time.After(current.Time) {
+		from, to = to, from
+	}
+	return from, to, log.SameRequest(previous, current), true
+}
+
+// Returns the lines that ran between the previous log of the same
+// request and the log at the cursor, when both map into the same function.
+func (m Model) executionPath() (analysis.ExecutionPath, bool) {
+	from, to, sameRequest, ok := m.adjacentSources()
+	if !ok || !sameRequest {
+		return analysis.ExecutionPath{}, false
+	}
+	return log.ExecutionPath(from, to)
+}
+
+// The call path between two sources, once it has been looked up.
+type callPathMsg struct {
+	key   string
+	steps []log.CallStep
+}
+
+// Looks up the chain of calls from the function of the previous log to
+// the function of the log at the cursor when either changed. Building
+// the call graph type checks the source, so it runs in the background
+// rather than while rendering.
+func (m *Model) fetchCallPath() tea.Cmd {
+	from, to, _, ok := m.adjacentSources()
+	key := ""
+	if ok {
+		key = fmt.Sprintf("%s:%d\x00%s:%d", from.Path, from.Line, to.Path, to.Line)
+	}
+	if key == m.callPathKey {
+		return nil
+	}
+	m.callPathKey, m.callSteps, m.callStep = key, nil, 0
+	if key == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		steps, _ := log.CallPath(from, to)
+		return callPathMsg{key, steps}
+	}
+}
+
+// Returns the chain of calls from the function of the previous log
+// to the function of the log at the cursor, once it was looked up.
+func (m Model) callPath() ([]log.CallStep, bool) {
+	return m.callSteps, len(m.callSteps) > 0
+}
+
+// Renders the call path as handleLogin ↩ serve → authenticate, with
+// ↩ where execution returned to a caller. The current step is underlined.
+func renderBreadcrumb(steps []log.CallStep, current int) string {
+	parts := []string{}
+	for i, s := range steps {
+		name := s.Function
+		if i == current-1 {
+			name = lipgloss.NewStyle().Underline(true).Render(name)
+		}
+		if i > 0 {
+			arrow := " → "
+			if s.Returned {
+				arrow = " ↩ "
+			}
+			parts = append(parts, arrow)
+		}
+		parts = append(parts, name)
+	}
+	return breadcrumbStyle.Render(strings.Join(parts, "")) + subtleStyle.Render("  ('[' / ']' to step)")
+}
@@ -9,11 +9,13 @@ import (
 
 	"slices"
 
+	"vlsa/internal/analysis"
 	"vlsa/internal/bus"
 	"vlsa/internal/log"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -36,6 +38,10 @@ var (
 	mainStyle          = lipgloss.NewStyle().MarginLeft(2)
 	focusedWindowStyle = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("63"))
 	modelStyle         = lipgloss.NewStyle().BorderStyle(lipgloss.HiddenBorder())
+	currentLineStyle   = lipgloss.NewStyle().Background(lipgloss.Color("57")).Foreground(lipgloss.Color("229"))
+	ranLineStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("156"))
+	skippedLineStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+	missingLineStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
 )
 
 // SourceItem represents an item in the source selector list
@@ -43,17 +49,39 @@ type SourceItem struct {
 	path string
 	line int
 	idx  int
+
+	function string
+	callers  int  // Number of callers when the source is in a logging wrapper
+	caller   bool // Whether the source is a caller listed under its wrapper
 }
 
 func (s SourceItem) FilterValue() string { return s.path }
-func (s SourceItem) Title() string       { return fmt.Sprintf("%s:%d", s.path, s.line) }
-func (s SourceItem) Description() string { return "Source file location" }
+func (s SourceItem) Title() string {
+	if s.caller {
+		return fmt.Sprintf("  ↳ %s:%d", s.path, s.line)
+	}
+	return fmt.Sprintf("%s:%d", s.path, s.line)
+}
+func (s SourceItem) Description() string {
+	desc := "Source file location"
+	if s.function != "" {
+		desc = s.function
+	}
+	if s.callers > 0 {
+		desc += fmt.Sprintf(" (logging wrapper, %d callers - 'e' to expand)", s.callers)
+	}
+	if s.caller {
+		desc = "  " + desc
+	}
+	return desc
+}
 
 // Model of the application state
 type Model struct {
 	// Application state
 	logs          []log.Log
 	currentLogIdx int
+	visible       []int // Indices of the logs shown in the table after filtering
 
 	// UI specific fields
 	x                  int
@@ -61,11 +89,25 @@ type Model struct {
 	logTable           table.Model
 	sourcesView        viewport.Model
 	sourceSelector     list.Model
+	filterInput        textinput.Model
+	filtering          bool // Whether the filter input has focus
+	columns            []logColumn
+	hideColumns        bool // Whether the optional columns are hidden
+	callStep           int  // Step of the call path from the previous log shown, 0 shows the log's source
+	showCoverage       bool // Whether the coverage report is shown in place of the panes
+	showBlame          bool // Whether who last changed the source's line is shown above it
+	coverageView       viewport.Model
 	selectedSourceIdx  int              // Track which source is selected for current log
 	showSourceSelector bool             // Whether to show the selector pane
 	currentWindow      int              // 0=logs, 1=sources, 2=selector
 	progress           int
 	quit               bool
+
+	// Looked up in the background for the selected source
+	callSteps   []log.CallStep
+	callPathKey string    // Sources the call path was looked up between
+	blame       *blameMsg // Blame of the selected source, nil while it is looked up
+	blameKey    string    // Source the blame was looked up for
 }
 
 func (m Model) Init() tea.Cmd {
@@ -73,14 +115,69 @@ func (m Model) Init() tea.Cmd {
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Details of sources that are no longer selected are dropped
+	switch msg := msg.(type) {
+	case callPathMsg:
+		if msg.key == m.callPathKey {
+			m.callSteps = msg.steps
+		}
+		return m, nil
+	case blameMsg:
+		if msg.key == m.blameKey {
+			m.blame = &msg
+		}
+		return m, nil
+	}
+
+	m, cmd := m.update(msg)
+	// Details of the selection that are slow to find are looked up in the background
+	return m, tea.Batch(cmd, m.fetchCallPath(), m.fetchBlame())
+}
+
+func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	
+	// While filtering every key goes to the filter input
+	if key, ok := msg.(tea.KeyMsg); ok && m.filtering {
+		switch key.String() {
+		case "enter":
+			m.filtering = false
+			m.filterInput.Blur()
+		case "esc":
+			m.filtering = false
+			m.filterInput.Blur()
+			m.filterInput.SetValue("")
+		default:
+			m.filterInput, cmd = m.filterInput.Update(msg)
+		}
+		m.applyFilter()
+		return m, cmd
+	}
+
+	// The coverage report takes every key but the ones closing it
+	if key, ok := msg.(tea.KeyMsg); ok && m.showCoverage {
+		switch key.String() {
+		case "v", "esc":
+			m.showCoverage = false
+		case "ctrl+c", "q":
+			m.quit = true
+			return m, tea.Quit
+		default:
+			m.coverageView, cmd = m.coverageView.Update(msg)
+		}
+		return m, cmd
+	}
+
 	// Update the appropriate component based on current window
 	switch m.currentWindow {
 	case 0: // Logs table
+		cursor := m.logTable.Cursor()
 		m.logTable, cmd = m.logTable.Update(msg)
+		if m.logTable.Cursor() != cursor {
+			m.callStep = 0
+		}
 		// Check if we need to update source selector when log changes
-		if len(m.logs) > 0 && m.logTable.Cursor() < len(m.logs) {
+		if m.cursorLog() >= 0 {
 			m.updateSourceSelector()
 		}
 	case 1: // Sources view
@@ -94,10 +191,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.progress = msg.Progress
 		if m.progress >= 100 {
 			m.logs = msg.Logs
-			m.logTable = createLogTable(m.logs)
+			m.logTable = createLogTable()
+			m.columns = presentColumns(m.logs)
 			m.logTable.KeyMap.HalfPageDown.SetEnabled(false)
 			m.sourcesView = viewport.New(m.getSourcesViewWidth(), m.y-3)
-			m.updateSourceSelector()
+			m.filterInput = newFilterInput()
+			m.applyFilter()
 		}
 		return m, nil
 
@@ -115,8 +214,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Select source and return to source view
 				if selectedItem, ok := m.sourceSelector.SelectedItem().(SourceItem); ok {
 					// Update the current log's selected source index
-					if m.logTable.Cursor() < len(m.logs) {
-						m.logs[m.logTable.Cursor()].SelectedSourceIdx = selectedItem.idx
+					if idx := m.cursorLog(); idx >= 0 {
+						m.logs[idx].SelectedSourceIdx = selectedItem.idx
 					}
 					m.showSourceSelector = false
 					m.currentWindow = 1 // Switch back to source view
@@ -128,7 +227,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Show source selector if multiple sources available
 		case "s":
-			if m.currentWindow == 1 && len(m.logs) > 0 && len(m.logs[m.logTable.Cursor()].Sources) > 1 {
+			if idx := m.cursorLog(); m.currentWindow == 1 && idx >= 0 && len(m.logs[idx].Sources) > 1 {
 				m.showSourceSelector = true
 				m.currentWindow = 2
 			}
@@ -148,17 +247,80 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.currentWindow = 1
 			}
 
+		// Expand or collapse the callers of a logging wrapper
+		case "e":
+			if idx := m.cursorLog(); idx >= 0 && (m.currentWindow == 1 || m.currentWindow == 2) {
+				l := &m.logs[idx]
+				i := min(l.SelectedSourceIdx, len(l.Sources)-1)
+				if m.currentWindow == 2 {
+					if item, ok := m.sourceSelector.SelectedItem().(SourceItem); ok {
+						i = item.idx
+					}
+				}
+				if i >= 0 && len(l.Sources[i].Callers) > 0 {
+					toggleCallers(l, i)
+					m.updateSourceSelector()
+					// Callers are picked from the selector
+					if len(l.Sources) > 1 {
+						m.showSourceSelector = true
+						m.currentWindow = 2
+						m.sourceSelector.Select(i)
+					}
+				}
+			}
+
+		// Step through the sources, or the frames of a goroutine's stack
+		case "n", "p":
+			if idx := m.cursorLog(); m.currentWindow == 1 && idx >= 0 && len(m.logs[idx].Sources) > 1 {
+				step := 1
+				if msg.String() == "p" {
+					step = -1
+				}
+				l := &m.logs[idx]
+				l.SelectedSourceIdx = (min(l.SelectedSourceIdx, len(l.Sources)-1) + step + len(l.Sources)) % len(l.Sources)
+			}
+
+		// Step through the call path from the previous log
+		case "[", "]":
+			if steps, ok := m.callPath(); ok {
+				if msg.String() == "]" {
+					m.callStep = (m.callStep + 1) % (len(steps) + 1)
+				} else {
+					m.callStep = (m.callStep + len(steps)) % (len(steps) + 1)
+				}
+			}
+
+		// Show or hide who changed the source's line and when
+		case "b":
+			if m.currentWindow == 1 {
+				m.showBlame = !m.showBlame
+			}
+
+		// Show or hide the level, service, host and trace columns
+		case "c":
+			if m.currentWindow == 0 {
+				m.hideColumns = !m.hideColumns
+				m.applyFilter()
+			}
+
+		// Show which log statements in the source were seen
+		case "v":
+			if m.currentWindow == 0 {
+				m.showCoverageReport()
+			}
+
+		// Filter logs by host, app or message
+		case "/":
+			if m.currentWindow == 0 {
+				m.filtering = true
+				m.filterInput.Focus()
+			}
+
 		// Delete log from records
 		case "d", "delete", "backspace":
-			if len(m.logs) > 1 && m.currentWindow == 0 {
-				m.logs = slices.Delete(m.logs, m.logTable.Cursor(), m.logTable.Cursor()+1)
-				if m.logTable.Cursor() >= len(m.logs) && len(m.logs) > 0 {
-					m.logTable.SetCursor(len(m.logs) - 1)
-				} else if len(m.logs) == 0 {
-					return nil, tea.Quit
-				}
-				m.logTable.SetRows(slices.Delete(m.logTable.Rows(), m.logTable.Cursor(), m.logTable.Cursor()+1))
-				m.updateSourceSelector()
+			if idx := m.cursorLog(); len(m.logs) > 1 && m.currentWindow == 0 && idx >= 0 {
+				m.logs = slices.Delete(m.logs, idx, idx+1)
+				m.applyFilter()
 			}
 
 		// Quit
@@ -171,31 +333,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.x, m.y = msg.Width, msg.Height
 		m.sourcesView.Width = m.getSourcesViewWidth()
 		m.sourcesView.Height = m.y - 3
+		m.coverageView.Width, m.coverageView.Height = m.x-2, m.y-3
 	}
 
 	return m, cmd
 }
 
-func createLogTable(logs []log.Log) table.Model {
-	// Convert to table rows
-	var rows []table.Row
-	for _, log := range logs {
-		row := table.Row{
-			fmt.Sprintf("%v", log.Time),
-			log.Message,
-		}
-		rows = append(rows, row)
-
-	}
-	columns := []table.Column{
-		{Title: "Timestamp", Width: 20},
-		{Title: "Log", Width: 20},
-	}
-
+// Creates the log table, rows are filled in by applyFilter.
+func createLogTable() table.Model {
 	// Create table
 	t := table.New(
-		table.WithColumns(columns),
-		table.WithRows(rows),
 		table.WithFocused(true),
 		table.WithWidth(30),
 	)
@@ -221,7 +368,14 @@ func (m Model) View() string {
 	}
 	
 	header := keywordStyle.Render("VLSA - Visual Log Source Analyzer")
+	if m.filtering || m.filterInput.Value() != "" {
+		header += "  " + m.filterInput.View() + subtleStyle.Render(fmt.Sprintf(" (%d of %d logs)", len(m.visible), len(m.logs)))
+	}
 	
+	if m.showCoverage {
+		return lipgloss.JoinVertical(lipgloss.Top, header, focusedWindowStyle.Render(m.coverageView.View()))
+	}
+
 	// Render based on whether source selector is shown
 	if m.showSourceSelector {
 		// Three-pane layout
@@ -281,19 +435,11 @@ func renderPrettySpinner(m Model) string {
 }
 
 func renderLogs(m Model) string {
-	width := (m.x / 2) - 2
-	if m.showSourceSelector {
-		width = (m.x / 3) - 2
-	}
+	width := m.getLogsViewWidth()
 	
 	m.logTable.SetWidth(width)
 	m.logTable.SetHeight(m.y - 4)
-
-	columns := []table.Column{
-		{Title: "Timestamp", Width: 20},
-		{Title: "Log", Width: width - 22},
-	}
-	m.logTable.SetColumns(columns)
+	m.logTable.SetColumns(m.tableColumns(width))
 	return m.logTable.View() + "\n"
 }
 
@@ -302,12 +448,13 @@ func renderSources(m Model) string {
 	m.sourcesView.Width = width
 	m.sourcesView.Height = m.y - 4
 	
-	if len(m.logs) == 0 || m.logTable.Cursor() >= len(m.logs) {
+	idx := m.cursorLog()
+	if idx < 0 {
 		m.sourcesView.SetContent("No logs available")
 		return m.sourcesView.View()
 	}
 	
-	currentLog := m.logs[m.logTable.Cursor()]
+	currentLog := m.logs[idx]
 	if len(currentLog.Sources) == 0 {
 		m.sourcesView.SetContent("No source code available")
 		return m.sourcesView.View()
@@ -320,13 +467,68 @@ func renderSources(m Model) string {
 	}
 	
 	source := currentLog.Sources[sourceIdx]
-	content := setSourceCodeView(source.SourceCode, source.Line, m.y-4)
+	path, onPath := m.executionPath()
 	
+	// Stepping through the call path shows the functions in between
+	steps, hasCallPath := m.callPath()
+	if hasCallPath && m.callStep > 0 && m.callStep <= len(steps) {
+		step := steps[m.callStep-1]
+		content := setSourceCodeView(step.SourceCode, step.Line, m.y-5, analysis.ExecutionPath{}, nil)
+		header := fmt.Sprintf("Call path step %d of %d: %s:%d - %s", m.callStep, len(steps), step.Path, step.Line, step.DisplayMessage)
+		m.sourcesView.SetContent(subtleStyle.Render(header) + "\n" + renderBreadcrumb(steps, m.callStep) + "\n" + content)
+		return m.sourcesView.View()
+	}
+
+	// Logs that should have followed this one in its function but didn't
+	missing := map[int]bool{}
+	for _, s := range currentLog.Missing {
+		if s.Path == source.Path {
+			missing[s.Line] = true
+		}
+	}
+	height := m.y - 4
+	blame := ""
+	if m.showBlame && source.Path != "" {
+		blame = renderBlame(m.blame, width)
+		height -= strings.Count(blame, "\n") + 1
+	}
+	content := setSourceCodeView(source.SourceCode, source.Line, height, path, missing)
+	if blame != "" {
+		content = blame + "\n" + content
+	}
+	if hasCallPath {
+		content = renderBreadcrumb(steps, 0) + "\n" + content
+	}
+
 	// Add header showing current source
-	header := fmt.Sprintf("Source: %s:%d", source.Path, source.Line)
-	if len(currentLog.Sources) > 1 {
+	rev, file := log.SplitRevision(source.Path)
+	if source.Module != "" {
+		file = source.DisplayPath()
+	}
+	header := fmt.Sprintf("Source: %s:%d", file, source.Line)
+	if symbol := source.Symbol(); symbol != "" {
+		header += " in " + symbol
+	}
+	if rev != "" {
+		header += " at commit " + rev[:8]
+	}
+	if source.Changed {
+		header += " • changed since --since"
+	}
+	if len(source.Callers) > 0 && expandedCallers(currentLog.Sources, sourceIdx) == 0 {
+		header += fmt.Sprintf(" [logging wrapper, %d callers - press 'e' to show]", len(source.Callers))
+	}
+	if len(currentLog.Stack) > 0 && len(currentLog.Sources) > 1 {
+		header += fmt.Sprintf(" (frame %d of %d - press 'n'/'p' to step)", sourceIdx+1, len(currentLog.Sources))
+	} else if len(currentLog.Sources) > 1 {
 		header += fmt.Sprintf(" (%d of %d sources - press 's' to select)", sourceIdx+1, len(currentLog.Sources))
 	}
+	if onPath {
+		header += fmt.Sprintf(" • %d lines ran since the previous log", len(path.Ran))
+	}
+	if len(missing) > 0 {
+		header += fmt.Sprintf(" • %d logs expected, not seen", len(missing))
+	}
 	content = subtleStyle.Render(header) + "\n" + content
 	
 	m.sourcesView.SetContent(content)
@@ -345,43 +547,69 @@ func renderSourceSelector(m Model) string {
 	m.sourceSelector.SetHeight(height)
 	
 	// Add instructions at the bottom
-	instructions := subtleStyle.Render("↑↓: Navigate • Enter: Select • A: Apply to similar • Esc: Cancel")
+	instructions := subtleStyle.Render("↑↓: Navigate • Enter: Select • E: Expand callers • A: Apply to similar • Esc: Cancel")
 	
 	return m.sourceSelector.View() + "\n" + instructions
 }
 
-func setSourceCodeView(sourceCode string, line, height int) string {
-	s := "No source code available"
+func setSourceCodeView(sourceCode string, line, height int, path analysis.ExecutionPath, missing map[int]bool) string {
 	if len(sourceCode) == 0 {
 		return "No source code available"
 	}
 
 	lines := strings.Split(sourceCode, "\n")
+	line = max(min(line, len(lines)), 1)
 
-	half := height / 2
-	if len(lines) < height {
-		s = strings.Join(lines[:line-1], "\n")
-		s += "\n" + lipgloss.NewStyle().Background(lipgloss.Color("57")).Foreground(lipgloss.Color("229")).Render(lines[line-1]) + "\n"
-		s += strings.Join(lines[line:], "\n")
-		return s
-	} else {
-		start := max(line-half, 0)
-		end := min(line+half, len(lines))
-		s = strings.Join(lines[start:line-1], "\n")
-		s += "\n" + lipgloss.NewStyle().Background(lipgloss.Color("57")).Foreground(lipgloss.Color("229")).Render(lines[line-1]) + "\n"
-		s += strings.Join(lines[line:end], "\n")
-		return s
+	start, end := 0, len(lines)
+	if len(lines) >= height {
+		half := height / 2
+		start = max(line-half, 0)
+		end = min(line+half, len(lines))
+	}
+
+	// Lines that ran since the previous log of the request are
+	// brightened and branches that could not have run are dimmed
+	view := []string{}
+	for i := start; i < end; i++ {
+		switch n := i + 1; {
+		case n == line:
+			view = append(view, currentLineStyle.Render(lines[i]))
+		case missing[n]:
+			view = append(view, missingLineStyle.Render(lines[i])+subtleStyle.Render("  ← expected, not seen"))
+		case path.Ran[n]:
+			view = append(view, ranLineStyle.Render(lines[i]))
+		case path.Skipped[n]:
+			view = append(view, skippedLineStyle.Render(lines[i]))
+		default:
+			view = append(view, lines[i])
+		}
 	}
+	return strings.Join(view, "\n")
 }
 
 // Helper methods for Model
 
+// Returns the selected source of the log at the cursor.
+func (m Model) currentSource() (log.SourceMapping, bool) {
+	idx := m.cursorLog()
+	if idx < 0 || len(m.logs[idx].Sources) == 0 {
+		return log.SourceMapping{}, false
+	}
+	l := m.logs[idx]
+	i := l.SelectedSourceIdx
+	if i >= len(l.Sources) {
+		i = 0
+	}
+	return l.Sources[i], true
+}
+
 func (m *Model) updateSourceSelector() {
-	if len(m.logs) == 0 || m.logTable.Cursor() >= len(m.logs) {
+	idx := m.cursorLog()
+	if idx < 0 {
 		return
 	}
 	
-	currentLog := m.logs[m.logTable.Cursor()]
+	currentLog := m.logs[idx]
 	if len(currentLog.Sources) <= 1 {
 		m.showSourceSelector = false
 		return
@@ -391,9 +619,13 @@ func (m *Model) updateSourceSelector() {
 	var items []list.Item
 	for i, source := range currentLog.Sources {
 		items = append(items, SourceItem{
-			path: source.Path,
+			path: source.DisplayPath(), // Dependencies are labeled with their module
 			line: source.Line,
 			idx:  i,
+
+			function: source.Symbol(),
+			callers:  len(source.Callers),
+			caller:   isExpandedCaller(currentLog.Sources, i),
 		})
 	}
 
@@ -403,6 +635,13 @@ func (m *Model) updateSourceSelector() {
 	m.sourceSelector.SetFilteringEnabled(false)
 }
 
+func (m Model) getLogsViewWidth() int {
+	if m.showSourceSelector {
+		return (m.x / 3) - 2 // Three pane layout
+	}
+	return (m.x / 2) - 2 // Two pane layout
+}
+
 func (m *Model) getSourcesViewWidth() int {
 	if m.showSourceSelector {
 		return (m.x / 3) - 2 // Three pane layout
@@ -429,11 +668,12 @@ func (m *Model) prevWindow() {
 }
 
 func (m *Model) openCurrentSourceInEditor() {
-	if len(m.logs) == 0 || m.logTable.Cursor() >= len(m.logs) {
+	idx := m.cursorLog()
+	if idx < 0 {
 		return
 	}
 	
-	currentLog := m.logs[m.logTable.Cursor()]
+	currentLog := m.logs[idx]
 	if len(currentLog.Sources) == 0 {
 		return
 	}
@@ -468,11 +708,12 @@ func (m *Model) openCurrentSourceInEditor() {
 }
 
 func (m *Model) applySourceToSimilarLogs() {
-	if len(m.logs) == 0 || m.logTable.Cursor() >= len(m.logs) {
+	idx := m.cursorLog()
+	if idx < 0 {
 		return
 	}
 	
-	currentLog := m.logs[m.logTable.Cursor()]
+	currentLog := m.logs[idx]
 	currentMessage := currentLog.Message
 	selectedSourceIdx := currentLog.SelectedSourceIdx
 	
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"vlsa/internal/log"
+)
+
+// Collects repeated --root dir flags.
+type dirFlags []string
+
+func (d *dirFlags) String() string { return strings.Join(*d, ",") }
+
+func (d *dirFlags) Set(v string) error {
+	// Roots given as service=dir for the other commands work here too
+	if _, dir, found := strings.Cut(v, "="); found {
+		v = dir
+	}
+	*d = append(*d, v)
+	return nil
+}
+
+// Lists the log statements in the source and flags the ones whose logs
+// can't be mapped back to them. Exits with 1 when any are found.
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	dirs := dirFlags{}
+	fs.Var(&dirs, "root", "source directory to check (repeatable, defaults to the current directory)")
+	output := fs.String("output", "text", "output format, one of: text, json, sarif")
+	all := fs.Bool("all", false, "list every log statement in the text output, not only the ones with issues")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: vlsa lint [flags]\n\nFlags log statements whose messages are not unique, too generic to search for or mostly dynamic.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if len(dirs) == 0 {
+		dirs = append(dirs, ".")
+	}
+
+	flush := drainBus()
+	results := log.Lint(dirs)
+	flush()
+	var err error
+	switch *output {
+	case "text":
+		log.WriteLintText(os.Stdout, results, *all)
+	case "json":
+		err = log.WriteLintJSON(os.Stdout, results)
+	case "sarif":
+		err = log.WriteLintSARIF(os.Stdout, results)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown output format %q, expected one of: text, json, sarif\n", *output)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if log.LintIssues(results) > 0 {
+		os.Exit(1)
+	}
+}
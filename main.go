@@ -1,8 +1,10 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"vlsa/internal/bus"
 	"vlsa/internal/log"
@@ -11,7 +13,109 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// Collects repeated --root [service=]dir flags.
+type rootFlags map[string]string
+
+func (r rootFlags) String() string {
+	roots := []string{}
+	for service, dir := range r {
+		roots = append(roots, service+"="+dir)
+	}
+	return strings.Join(roots, ",")
+}
+
+func (r rootFlags) Set(v string) error {
+	service, dir, found := strings.Cut(v, "=")
+	if !found {
+		service, dir = "", v
+	}
+	if dir == "" {
+		return fmt.Errorf("missing directory in %q", v)
+	}
+	r[service] = dir
+	return nil
+}
+
+// Flags every command uses to find and parse the logs.
+type sourceOptions struct {
+	roots    rootFlags
+	format   *string
+	deployed *bool
+	deploys  *string
+	since    *string
+	deps     *bool
+}
+
+func sourceFlags(fs *flag.FlagSet) *sourceOptions {
+	s := &sourceOptions{roots: rootFlags{}}
+	fs.Var(s.roots, "root", "source directory to search, as dir or service=dir to map a service's logs to its own source (repeatable)")
+	s.format = fs.String("format", "", "log format to parse, one of: "+strings.Join(log.ParserNames(), ", ")+" (detected when empty)")
+	s.deployed = fs.Bool("deployed", false, "map each log against the git commit in its version or build SHA attribute instead of the working tree")
+	s.deploys = fs.String("deploys", "", "deployment timeline with a timestamp and commit per line, for logs without a version attribute (implies --deployed)")
+	s.since = fs.String("since", "", "mark logs whose source line or function changed between this git revision and HEAD")
+	s.deps = fs.Bool("deps", false, "also search the Go modules required by each root's go.mod, in vendor/ or the module cache, after the root itself")
+	return s
+}
+
+// Returns the options for processing the logs, reading the deployment timeline.
+func (s *sourceOptions) options() (log.Options, error) {
+	opts := log.Options{Format: *s.format, SourceRoots: s.roots, Deployed: *s.deployed || *s.deploys != "", Since: *s.since, Dependencies: *s.deps}
+	if *s.deploys != "" {
+		deploys, err := log.ReadDeploys(*s.deploys)
+		if err != nil {
+			return opts, err
+		}
+		opts.Deploys = deploys
+	}
+	return opts, nil
+}
+
+// Commands run with vlsa <command>, without one the logs are browsed.
+var commands = map[string]func(args []string){
+	"coverage": runCoverage,
+	"lint":     runLint,
+	"verify":   runVerify,
+}
+
+// Reads the bus for as long as a command without the TUI runs, so
+// sending to it never blocks. Errors go to stderr, progress is dropped.
+// Returns a function that waits for the errors sent so far to be written.
+func drainBus() func() {
+	go func() {
+		for msg := range bus.LogChannel {
+			if strings.HasPrefix(msg, "Error") {
+				fmt.Fprintln(os.Stderr, msg)
+			}
+		}
+	}()
+	// The bus is unbuffered, once another message is taken the last one was written
+	return func() { bus.LogChannel <- "" }
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			run(os.Args[2:])
+			return
+		}
+	}
+
+	source := sourceFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: vlsa [flags] <log file>\n       vlsa coverage [flags] <log file>\n       vlsa lint [flags]\n       vlsa verify --logs <log file> [flags]\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	opts, err := source.options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	model := tui.Model{}
 
 	p := tea.NewProgram(model)
@@ -23,7 +127,7 @@ func main() {
 				p.Send(msg)
 			}
 		}()
-		log.ProcessLogs(os.Args[1], logChannel)
+		log.ProcessLogs(flag.Arg(0), opts, logChannel)
 	}()
 
 	appLogs := make(chan string)
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"vlsa/internal/log"
+)
+
+// Checks that the templates of a reference log file still map to the
+// functions recorded in the contract. Exits with 1 when one regressed.
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	source := sourceFlags(fs)
+	logsPath := fs.String("logs", "", "reference log file whose templates are checked")
+	contractPath := fs.String("contract", "vlsa-contract.json", "where the mapping of each template is recorded")
+	update := fs.Bool("update", false, "record the current mapping as the contract instead of checking it")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: vlsa verify --logs <log file> [flags]\n\nReports log templates whose source disappeared, moved to another function or became ambiguous.\nThe first run records the contract the following runs are checked against.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *logsPath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	opts, err := source.options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	flush := drainBus()
+	logs, err := loadLogs(*logsPath, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	current := log.Contract(logs)
+	flush()
+
+	if _, err := os.Stat(*contractPath); *update || os.IsNotExist(err) {
+		if err := log.WriteContract(*contractPath, current); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		fmt.Printf("Recorded %d templates in %s\n", len(current), *contractPath)
+		return
+	}
+
+	recorded, err := log.ReadContract(*contractPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	regressed := 0
+	for _, c := range log.VerifyContract(recorded, current) {
+		if c.Regressed() {
+			regressed++
+		}
+		service := ""
+		if c.Service != "" {
+			service = c.Service + ": "
+		}
+		fmt.Printf("%-11s %s%q\n            %s\n", c.Kind, service, c.Template, c.Detail)
+	}
+	fmt.Printf("%d of %d templates regressed\n", regressed, len(current))
+	if regressed > 0 {
+		os.Exit(1)
+	}
+}